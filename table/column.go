@@ -23,9 +23,7 @@ import (
 	"strconv"
 	"strings"
 	"time"
-	"unicode"
 
-	"github.com/pingcap/tidb/config"
 	"github.com/pingcap/tidb/expression"
 	"github.com/pingcap/tidb/parser"
 	"github.com/pingcap/tidb/parser/ast"
@@ -172,21 +170,8 @@ func truncateTrailingSpaces(v *types.Datum) {
 
 func handleWrongCharsetValue(ctx sessionctx.Context, col *model.ColumnInfo, str string, i int) error {
 	sc := ctx.GetSessionVars().StmtCtx
-	var strval strings.Builder
-	for j := 0; j < 6; j++ {
-		if len(str) > (i + j) {
-			if str[i+j] > unicode.MaxASCII {
-				fmt.Fprintf(&strval, "\\x%X", str[i+j])
-			} else {
-				strval.WriteRune(rune(str[i+j]))
-			}
-		}
-	}
-	if len(str) > i+6 {
-		strval.WriteString(`...`)
-	}
 	// TODO: Add 'at row %d'
-	err := ErrTruncatedWrongValueForField.FastGen("Incorrect string value '%s' for column '%s'", strval.String(), col.Name)
+	err := ErrTruncatedWrongValueForField.FastGen("Incorrect string value '%s' for column '%s'", types.FormatInvalidStrValue(str, i), col.Name)
 	logutil.BgLogger().Error("incorrect string value", zap.Uint64("conn", ctx.GetSessionVars().ConnectionID), zap.Error(err))
 	err = sc.HandleTruncate(err)
 	return err
@@ -337,23 +322,12 @@ func makeStringValidator(ctx sessionctx.Context, col *model.ColumnInfo) charset.
 		if ctx.GetSessionVars().SkipASCIICheck {
 			return nil
 		}
-		return charset.StringValidatorASCII{}
-	case charset.CharsetUTF8:
-		if ctx.GetSessionVars().SkipUTF8Check {
-			return nil
-		}
-		needCheckMB4 := config.GetGlobalConfig().CheckMb4ValueInUTF8
-		return charset.StringValidatorUTF8{IsUTF8MB4: false, CheckMB4ValueInUTF8: needCheckMB4}
-	case charset.CharsetUTF8MB4:
+	case charset.CharsetUTF8, charset.CharsetUTF8MB4:
 		if ctx.GetSessionVars().SkipUTF8Check {
 			return nil
 		}
-		return charset.StringValidatorUTF8{IsUTF8MB4: true}
-	case charset.CharsetLatin1, charset.CharsetBinary:
-		return nil
-	default:
-		return charset.StringValidatorOther{Charset: col.Charset}
 	}
+	return types.NewStringValidator(col.Charset)
 }
 
 // ColDesc describes column information like MySQL desc and show columns do.
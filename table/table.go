@@ -87,7 +87,7 @@ var (
 	// ErrInvalidRecordKey returns for invalid record key.
 	ErrInvalidRecordKey = dbterror.ClassTable.NewStd(mysql.ErrInvalidRecordKey)
 	// ErrTruncatedWrongValueForField returns for truncate wrong value for field.
-	ErrTruncatedWrongValueForField = dbterror.ClassTable.NewStd(mysql.ErrTruncatedWrongValueForField)
+	ErrTruncatedWrongValueForField = types.ErrTruncatedWrongValueForField
 	// ErrUnknownPartition returns unknown partition error.
 	ErrUnknownPartition = dbterror.ClassTable.NewStd(mysql.ErrUnknownPartition)
 	// ErrNoPartitionForGivenValue returns table has no partition for value.
@@ -25,6 +25,7 @@ import (
 	"github.com/pingcap/tidb/parser/mysql"
 	"github.com/pingcap/tidb/parser/terror"
 	"github.com/pingcap/tidb/sessionctx/stmtctx"
+	"github.com/pingcap/tidb/testkit/trequire"
 	"github.com/pingcap/tidb/types"
 	"github.com/pingcap/tidb/util/codec"
 	"github.com/pingcap/tidb/util/collate"
@@ -169,9 +170,7 @@ func TestDecodeColumnValue(t *testing.T) {
 	tp := types.NewFieldType(mysql.TypeTimestamp)
 	d1, err := DecodeColumnValue(bs, tp, sc.TimeZone)
 	require.NoError(t, err)
-	cmp, err := d1.Compare(sc, &d, collate.GetBinaryCollator())
-	require.NoError(t, err)
-	require.Equal(t, 0, cmp)
+	trequire.AssertCompareSymmetric(t, sc, d1, d, collate.GetBinaryCollator(), 0)
 
 	// test set
 	elems := []string{"a", "b", "c", "d", "e"}
@@ -186,9 +185,7 @@ func TestDecodeColumnValue(t *testing.T) {
 	tp.Elems = elems
 	d1, err = DecodeColumnValue(bs, tp, sc.TimeZone)
 	require.NoError(t, err)
-	cmp, err = d1.Compare(sc, &d, collate.GetCollator(tp.Collate))
-	require.NoError(t, err)
-	require.Equal(t, 0, cmp)
+	trequire.AssertCompareSymmetric(t, sc, d1, d, collate.GetCollator(tp.Collate), 0)
 
 	// test bit
 	d = types.NewMysqlBitDatum(types.NewBinaryLiteralFromUint(3223600, 3))
@@ -201,9 +198,7 @@ func TestDecodeColumnValue(t *testing.T) {
 	tp.Flen = 24
 	d1, err = DecodeColumnValue(bs, tp, sc.TimeZone)
 	require.NoError(t, err)
-	cmp, err = d1.Compare(sc, &d, collate.GetBinaryCollator())
-	require.NoError(t, err)
-	require.Equal(t, 0, cmp)
+	trequire.AssertCompareSymmetric(t, sc, d1, d, collate.GetBinaryCollator(), 0)
 
 	// test empty enum
 	d = types.NewMysqlEnumDatum(types.Enum{})
@@ -215,9 +210,7 @@ func TestDecodeColumnValue(t *testing.T) {
 	tp = types.NewFieldType(mysql.TypeEnum)
 	d1, err = DecodeColumnValue(bs, tp, sc.TimeZone)
 	require.NoError(t, err)
-	cmp, err = d1.Compare(sc, &d, collate.GetCollator(tp.Collate))
-	require.NoError(t, err)
-	require.Equal(t, 0, cmp)
+	trequire.AssertCompareSymmetric(t, sc, d1, d, collate.GetCollator(tp.Collate), 0)
 }
 
 func TestUnflattenDatums(t *testing.T) {
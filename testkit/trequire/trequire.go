@@ -22,6 +22,7 @@ import (
 
 	"github.com/pingcap/tidb/sessionctx/stmtctx"
 	"github.com/pingcap/tidb/types"
+	"github.com/pingcap/tidb/util/collate"
 	"github.com/stretchr/testify/require"
 )
 
@@ -32,3 +33,21 @@ func DatumEqual(t *testing.T, expected, actual types.Datum, msgAndArgs ...interf
 	require.NoError(t, err, msgAndArgs)
 	require.Zero(t, res, msgAndArgs)
 }
+
+// AssertCompareSymmetric verifies that a.Compare(b) is expected and b.Compare(a) is -expected,
+// and that both a and b are equal to themselves.
+func AssertCompareSymmetric(t *testing.T, sc *stmtctx.StatementContext, a, b types.Datum, collator collate.Collator, expected int) {
+	res, err := a.Compare(sc, &b, collator)
+	require.NoError(t, err)
+	require.Equal(t, expected, res, "compare %v with %v", a, b)
+
+	res, err = b.Compare(sc, &a, collator)
+	require.NoError(t, err)
+	require.Equal(t, -expected, res, "compare %v with %v", b, a)
+
+	for _, d := range []types.Datum{a, b} {
+		res, err = d.Compare(sc, &d, collator)
+		require.NoError(t, err)
+		require.Zero(t, res, "compare %v with itself", d)
+	}
+}
@@ -0,0 +1,35 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !codes
+// +build !codes
+
+package trequire
+
+import (
+	"testing"
+
+	"github.com/pingcap/tidb/sessionctx/stmtctx"
+	"github.com/pingcap/tidb/types"
+	"github.com/pingcap/tidb/util/collate"
+)
+
+func TestAssertCompareSymmetric(t *testing.T) {
+	t.Parallel()
+	sc := new(stmtctx.StatementContext)
+	AssertCompareSymmetric(t, sc, types.NewIntDatum(1), types.NewIntDatum(2), collate.GetBinaryCollator(), -1)
+	AssertCompareSymmetric(t, sc, types.NewStringDatum("b"), types.NewStringDatum("a"), collate.GetBinaryCollator(), 1)
+	AssertCompareSymmetric(t, sc, types.NewIntDatum(1), types.NewFloat64Datum(1), collate.GetBinaryCollator(), 0)
+	AssertCompareSymmetric(t, sc, types.Datum{}, types.NewIntDatum(0), collate.GetBinaryCollator(), -1)
+}
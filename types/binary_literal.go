@@ -154,6 +154,21 @@ func ParseBitStr(s string) (BinaryLiteral, error) {
 		return nil, errors.Errorf("invalid bit type format %s", s)
 	}
 
+	return parseBitDigits(s)
+}
+
+// ParseBitString parses a string of binary digits without the b'val' or 0bval format, e.g. 101.
+// The digits are packed MSB-first and left-padded with zero bits to whole bytes.
+func ParseBitString(s string) (BinaryLiteral, error) {
+	for i := 0; i < len(s); i++ {
+		if s[i] != '0' && s[i] != '1' {
+			return nil, errors.Errorf("invalid bit string %s", s)
+		}
+	}
+	return parseBitDigits(s)
+}
+
+func parseBitDigits(s string) (BinaryLiteral, error) {
 	if len(s) == 0 {
 		return ZeroBinaryLiteral, nil
 	}
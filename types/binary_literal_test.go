@@ -16,8 +16,10 @@ package types
 
 import (
 	"fmt"
+	"math"
 	"testing"
 
+	"github.com/pingcap/tidb/parser/terror"
 	"github.com/pingcap/tidb/sessionctx/stmtctx"
 	"github.com/stretchr/testify/require"
 )
@@ -114,6 +116,30 @@ func TestBinaryLiteral(t *testing.T) {
 		require.Contains(t, err.Error(), "invalid empty ")
 	})
 
+	t.Run("TestParseBitString", func(t *testing.T) {
+		t.Parallel()
+		tbl := []struct {
+			Input    string
+			Expected []byte
+		}{
+			{"101", []byte{0x5}},
+			{"11111111", []byte{0xFF}},
+			{"100000000", []byte{0x1, 0x0}},
+			{"0", []byte{0x0}},
+			{"", []byte{}},
+		}
+		for _, item := range tbl {
+			b, err := ParseBitString(item.Input)
+			require.NoError(t, err)
+			require.Equal(t, item.Expected, []byte(b))
+		}
+
+		for _, input := range []string{"102", "b'101'", " 101", "1a"} {
+			_, err := ParseBitString(input)
+			require.Error(t, err)
+		}
+	})
+
 	t.Run("TestParseHexStr", func(t *testing.T) {
 		t.Parallel()
 		tbl := []struct {
@@ -227,6 +253,33 @@ func TestBinaryLiteral(t *testing.T) {
 		}
 	})
 
+	t.Run("TestToIntOverflow", func(t *testing.T) {
+		t.Parallel()
+		// Leading zero bytes don't count towards the 8 byte limit.
+		intValue, err := BinaryLiteral{0x00, 0x00, 0x80, 0, 0, 0, 0, 0, 0, 0x01}.ToInt(nil)
+		require.NoError(t, err)
+		require.Equal(t, uint64(0x8000000000000001), intValue)
+		intValue, err = BinaryLiteral{}.ToInt(nil)
+		require.NoError(t, err)
+		require.Equal(t, uint64(0), intValue)
+
+		tooLong := BinaryLiteral{0x01, 0, 0, 0, 0, 0, 0, 0, 0}
+		intValue, err = tooLong.ToInt(nil)
+		require.Truef(t, terror.ErrorEqual(err, ErrTruncatedWrongVal), "err %v", err)
+		require.Equal(t, uint64(math.MaxUint64), intValue)
+
+		// The statement context decides whether the truncation is an error or a warning.
+		sc := new(stmtctx.StatementContext)
+		_, err = tooLong.ToInt(sc)
+		require.Truef(t, terror.ErrorEqual(err, ErrTruncatedWrongVal), "err %v", err)
+		sc = &stmtctx.StatementContext{TruncateAsWarning: true}
+		intValue, err = tooLong.ToInt(sc)
+		require.NoError(t, err)
+		require.Equal(t, uint64(math.MaxUint64), intValue)
+		require.Equal(t, uint16(1), sc.WarningCount())
+		require.True(t, terror.ErrorEqual(sc.GetWarnings()[0].Err, ErrTruncatedWrongVal))
+	})
+
 	t.Run("TestNewBinaryLiteralFromUint", func(t *testing.T) {
 		t.Parallel()
 		tbl := []struct {
@@ -16,8 +16,11 @@ package types
 
 import (
 	"math"
+	"sync"
 	"time"
 
+	"github.com/pingcap/errors"
+	"github.com/pingcap/tidb/sessionctx/stmtctx"
 	"github.com/pingcap/tidb/util/collate"
 )
 
@@ -103,6 +106,58 @@ func VecCompareIU(x []int64, y []uint64, res []int64) {
 	}
 }
 
+// VecCompareFF returns []int64 comparing the []float64 x to []float64 y, the results are the same
+// as CompareFloat64, so -0.0 equals +0.0 and a comparison involving NaN returns 1.
+func VecCompareFF(x, y []float64, res []int64) {
+	n := len(x)
+	for i := 0; i < n; i++ {
+		if x[i] < y[i] {
+			res[i] = -1
+		} else if x[i] == y[i] {
+			res[i] = 0
+		} else {
+			res[i] = 1
+		}
+	}
+}
+
+// VecCompareDD returns []int64 comparing the []MyDecimal x to []MyDecimal y like MyDecimal.Compare,
+// but only the significant words are scanned rather than subtracting the decimals.
+func VecCompareDD(x, y []MyDecimal, res []int64) {
+	n := len(x)
+	for i := 0; i < n; i++ {
+		if x[i].negative != y[i].negative {
+			if x[i].negative {
+				res[i] = -1
+			} else {
+				res[i] = 1
+			}
+			continue
+		}
+		int1, frac1 := x[i].significantWords()
+		int2, frac2 := y[i].significantWords()
+		cmp := compareSignificantWords(int1, frac1, int2, frac2)
+		if x[i].negative {
+			cmp = -cmp
+		}
+		res[i] = int64(cmp)
+	}
+}
+
+// VecCompareString returns []int64 comparing the []string x to []string y with the collator,
+// the results are the same as collator.Compare, including the PAD SPACE of the collation.
+func VecCompareString(collator collate.Collator, x, y []string, res []int64) {
+	n := len(x)
+	for i := 0; i < n; i++ {
+		// Equal strings are equal under every collation, which is common for join keys.
+		if x[i] == y[i] {
+			res[i] = 0
+			continue
+		}
+		res[i] = int64(collator.Compare(x[i], y[i]))
+	}
+}
+
 // CompareFloat64 returns an integer comparing the float64 x to y.
 func CompareFloat64(x, y float64) int {
 	if x < y {
@@ -129,3 +184,175 @@ func CompareDuration(x, y time.Duration) int {
 
 	return 1
 }
+
+// CompareNullable compares a to b like Datum.Compare, but follows the SQL three-valued logic for
+// NULL: if either side is NULL, the comparison is unknown and isNull is true, rather than NULL
+// being less than any value. The MinNotNull and MaxValue sentinels are not NULL.
+func CompareNullable(sc *stmtctx.StatementContext, a, b *Datum, collator collate.Collator) (result int, isNull bool, err error) {
+	if a.IsNull() || b.IsNull() {
+		return 0, true, nil
+	}
+	result, err = a.Compare(sc, b, collator)
+	return result, false, errors.Trace(err)
+}
+
+// VecCompareDatum returns []int64 comparing the []Datum x to []Datum y like Datum.Compare. If
+// all the datums of x are of one kind and all of y are of one kind, e.g. an int column compared
+// to a broadcast decimal constant is not, the values are extracted and compared by the
+// specialized VecCompare* function of the kinds, otherwise every pair is compared by
+// Datum.Compare with the same sc, a nil sc is replaced by an empty one once for the whole batch.
+func VecCompareDatum(sc *stmtctx.StatementContext, x, y []Datum, collator collate.Collator, res []int64) error {
+	n := len(x)
+	if len(y) != n || len(res) < n {
+		return errors.Errorf("cannot compare %d datums to %d datums into %d results", n, len(y), len(res))
+	}
+	if n == 0 {
+		return nil
+	}
+	if vecCompareSameKind(x, y, collator, res) {
+		return nil
+	}
+	if sc == nil {
+		sc = new(stmtctx.StatementContext)
+	}
+	for i := range x {
+		cmp, err := x[i].Compare(sc, &y[i], collator)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		res[i] = int64(cmp)
+	}
+	return nil
+}
+
+// vecKind returns the kind of d for VecCompareDatum, the kinds which are compared the same way
+// are merged.
+func vecKind(d *Datum) byte {
+	switch d.k {
+	case KindFloat32:
+		return KindFloat64
+	case KindBytes:
+		return KindString
+	}
+	return d.k
+}
+
+// vecCompareBuf holds the typed slices vecCompareSameKind extracts the values into, it's pooled
+// so comparing a batch doesn't allocate them every time.
+type vecCompareBuf struct {
+	i64 [2][]int64
+	u64 [2][]uint64
+	f64 [2][]float64
+	dec [2][]MyDecimal
+	str [2][]string
+}
+
+var vecCompareBufPool = sync.Pool{New: func() interface{} { return new(vecCompareBuf) }}
+
+func growInt64s(s []int64, n int) []int64 {
+	if cap(s) < n {
+		return make([]int64, n)
+	}
+	return s[:n]
+}
+
+func growUint64s(s []uint64, n int) []uint64 {
+	if cap(s) < n {
+		return make([]uint64, n)
+	}
+	return s[:n]
+}
+
+func growFloat64s(s []float64, n int) []float64 {
+	if cap(s) < n {
+		return make([]float64, n)
+	}
+	return s[:n]
+}
+
+func growDecimals(s []MyDecimal, n int) []MyDecimal {
+	if cap(s) < n {
+		return make([]MyDecimal, n)
+	}
+	return s[:n]
+}
+
+func growStrings(s []string, n int) []string {
+	if cap(s) < n {
+		return make([]string, n)
+	}
+	return s[:n]
+}
+
+// vecCompareSameKind compares x to y into res by a VecCompare* function and returns true, if all
+// the datums of x are of one kind and all of y are of one kind, which the function supports.
+func vecCompareSameKind(x, y []Datum, collator collate.Collator, res []int64) bool {
+	xk, yk := vecKind(&x[0]), vecKind(&y[0])
+	for i := range x {
+		if vecKind(&x[i]) != xk || vecKind(&y[i]) != yk {
+			return false
+		}
+	}
+	n := len(x)
+	buf := vecCompareBufPool.Get().(*vecCompareBuf)
+	defer vecCompareBufPool.Put(buf)
+	switch {
+	case xk == KindInt64 && yk == KindInt64:
+		xs, ys := growInt64s(buf.i64[0], n), growInt64s(buf.i64[1], n)
+		buf.i64[0], buf.i64[1] = xs, ys
+		for i := range x {
+			xs[i], ys[i] = x[i].GetInt64(), y[i].GetInt64()
+		}
+		VecCompareII(xs, ys, res)
+	case xk == KindUint64 && yk == KindUint64:
+		xs, ys := growUint64s(buf.u64[0], n), growUint64s(buf.u64[1], n)
+		buf.u64[0], buf.u64[1] = xs, ys
+		for i := range x {
+			xs[i], ys[i] = x[i].GetUint64(), y[i].GetUint64()
+		}
+		VecCompareUU(xs, ys, res)
+	case xk == KindInt64 && yk == KindUint64:
+		xs, ys := growInt64s(buf.i64[0], n), growUint64s(buf.u64[1], n)
+		buf.i64[0], buf.u64[1] = xs, ys
+		for i := range x {
+			xs[i], ys[i] = x[i].GetInt64(), y[i].GetUint64()
+		}
+		VecCompareIU(xs, ys, res)
+	case xk == KindUint64 && yk == KindInt64:
+		xs, ys := growUint64s(buf.u64[0], n), growInt64s(buf.i64[1], n)
+		buf.u64[0], buf.i64[1] = xs, ys
+		for i := range x {
+			xs[i], ys[i] = x[i].GetUint64(), y[i].GetInt64()
+		}
+		VecCompareUI(xs, ys, res)
+	case xk == KindFloat64 && yk == KindFloat64:
+		// VecCompareFF treats NaN the same as CompareFloat64, which Datum.Compare uses.
+		xs, ys := growFloat64s(buf.f64[0], n), growFloat64s(buf.f64[1], n)
+		buf.f64[0], buf.f64[1] = xs, ys
+		for i := range x {
+			xs[i], ys[i] = x[i].GetFloat64(), y[i].GetFloat64()
+		}
+		VecCompareFF(xs, ys, res)
+	case xk == KindMysqlDecimal && yk == KindMysqlDecimal:
+		xs, ys := growDecimals(buf.dec[0], n), growDecimals(buf.dec[1], n)
+		buf.dec[0], buf.dec[1] = xs, ys
+		for i := range x {
+			xs[i], ys[i] = *x[i].GetMysqlDecimal(), *y[i].GetMysqlDecimal()
+		}
+		VecCompareDD(xs, ys, res)
+	case xk == KindString && yk == KindString:
+		xs, ys := growStrings(buf.str[0], n), growStrings(buf.str[1], n)
+		buf.str[0], buf.str[1] = xs, ys
+		for i := range x {
+			xs[i], ys[i] = x[i].GetString(), y[i].GetString()
+		}
+		VecCompareString(collator, xs, ys, res)
+		// Don't keep the strings alive in the pool.
+		for i := range xs {
+			xs[i], ys[i] = "", ""
+		}
+	default:
+		return false
+	}
+	return true
+}
@@ -0,0 +1,428 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package types
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/pingcap/tidb/sessionctx/stmtctx"
+	"github.com/pingcap/tidb/util/collate"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompareChecked(t *testing.T) {
+	collate.SetNewCollationEnabledForTest(true)
+	defer collate.SetNewCollationEnabledForTest(false)
+
+	sc := new(stmtctx.StatementContext)
+	ci := collate.GetCollator("utf8mb4_general_ci")
+	require.False(t, collate.IsBinCollator(ci))
+	require.True(t, collate.IsBinCollator(collate.GetCollator("utf8mb4_bin")))
+
+	// A non-binary collator between strings is fine.
+	a, b := NewStringDatum("a"), NewStringDatum("A")
+	ret, err := a.CompareChecked(sc, &b, ci)
+	require.NoError(t, err)
+	require.Equal(t, 0, ret)
+
+	// A non-binary collator for a numeric comparison is a misuse.
+	i, s := NewIntDatum(1), NewStringDatum("1")
+	_, err = i.CompareChecked(sc, &i, ci)
+	require.Error(t, err)
+	_, err = i.CompareChecked(sc, &s, ci)
+	require.Error(t, err)
+	_, err = s.CompareChecked(sc, &i, ci)
+	require.Error(t, err)
+
+	// Binary collators are always accepted, and Compare itself is unaffected.
+	ret, err = i.CompareChecked(sc, &s, collate.GetBinaryCollator())
+	require.NoError(t, err)
+	require.Equal(t, 0, ret)
+	ret, err = i.Compare(sc, &s, ci)
+	require.NoError(t, err)
+	require.Equal(t, 0, ret)
+}
+
+func TestCompareNoPad(t *testing.T) {
+	collate.SetNewCollationEnabledForTest(true)
+	defer collate.SetNewCollationEnabledForTest(false)
+
+	sc := new(stmtctx.StatementContext)
+	tbl := []struct {
+		lhs   string
+		rhs   string
+		coll  string
+		pad   int
+		noPad int
+	}{
+		{"a", "a ", "utf8mb4_bin", 0, -1},
+		{"a  ", "a ", "utf8mb4_bin", 0, 1},
+		{"a ", "b", "utf8mb4_bin", -1, -1},
+		{"A", "a ", "utf8mb4_general_ci", 0, -1},
+		{"a", "a", "utf8mb4_general_ci", 0, 0},
+		{"a", "a ", "binary", -1, -1},
+	}
+	for i, tt := range tbl {
+		lhs, rhs := NewStringDatum(tt.lhs), NewStringDatum(tt.rhs)
+		ret, err := lhs.Compare(sc, &rhs, collate.GetCollator(tt.coll))
+		require.NoError(t, err)
+		require.Equal(t, tt.pad, ret, "%d", i)
+
+		ret, err = lhs.CompareNoPad(sc, &rhs, collate.GetCollator(tt.coll))
+		require.NoError(t, err)
+		require.Equal(t, tt.noPad, ret, "%d", i)
+		ret, err = rhs.CompareNoPad(sc, &lhs, collate.GetCollator(tt.coll))
+		require.NoError(t, err)
+		require.Equal(t, -tt.noPad, ret, "%d", i)
+	}
+}
+
+func TestCommonPrefixLen(t *testing.T) {
+	collate.SetNewCollationEnabledForTest(true)
+	defer collate.SetNewCollationEnabledForTest(false)
+
+	tbl := []struct {
+		lhs  string
+		rhs  string
+		coll string
+		ret  int
+	}{
+		{"abc", "abd", "utf8mb4_bin", 2},
+		{"abc", "ABd", "utf8mb4_bin", 0},
+		{"abc", "ABd", "utf8mb4_general_ci", 2},
+		{"Äbc", "abd", "utf8mb4_general_ci", 2},
+		{"ab", "abc", "utf8mb4_bin", 2},
+		{"abc", "abc", "utf8mb4_bin", 3},
+		{"", "abc", "utf8mb4_bin", 0},
+		{"xyz", "abc", "utf8mb4_general_ci", 0},
+		{"中文字", "中文词", "utf8mb4_bin", 2},
+		{"中文", "中文字", "utf8mb4_general_ci", 2},
+		{"中文字", "中文词", "binary", 2},
+		{"a b", "a  ", "utf8mb4_bin", 2},
+	}
+	for i, tt := range tbl {
+		lhs, rhs := NewStringDatum(tt.lhs), NewStringDatum(tt.rhs)
+		ret, err := lhs.CommonPrefixLen(&rhs, collate.GetCollator(tt.coll))
+		require.NoError(t, err)
+		require.Equal(t, tt.ret, ret, "%d", i)
+		ret, err = rhs.CommonPrefixLen(&lhs, collate.GetCollator(tt.coll))
+		require.NoError(t, err)
+		require.Equal(t, tt.ret, ret, "%d", i)
+	}
+
+	s, i := NewStringDatum("1"), NewIntDatum(1)
+	_, err := s.CommonPrefixLen(&i, collate.GetBinaryCollator())
+	require.Error(t, err)
+	_, err = i.CommonPrefixLen(&s, collate.GetBinaryCollator())
+	require.Error(t, err)
+}
+
+func TestCompareCrossCharset(t *testing.T) {
+	collate.SetNewCollationEnabledForTest(true)
+	defer collate.SetNewCollationEnabledForTest(false)
+
+	sc := new(stmtctx.StatementContext)
+	tbl := []struct {
+		lhs        string
+		lhsCharset string
+		rhs        string
+		rhsCharset string
+		coll       string
+		ret        int
+	}{
+		// The side whose charset is contained by the other is converted, which never fails.
+		{"café", "latin1", "café", "utf8mb4", "utf8mb4_bin", 0},
+		{"CAFÉ", "latin1", "café", "utf8mb4", "utf8mb4_general_ci", 0},
+		{"abc", "latin1", "中文", "utf8mb4", "utf8mb4_bin", -1},
+		{"中文", "utf8mb4", "abc", "latin1", "utf8mb4_bin", 1},
+		{"中文", "gbk", "中文", "utf8", "utf8mb4_bin", 0},
+		{"a", "utf8", "😀", "utf8mb4", "utf8mb4_bin", -1},
+		{"abc", "ascii", "abd", "latin1", "utf8mb4_bin", -1},
+		// Neither contains the other, the rhs is converted to the charset of the lhs.
+		{"中文", "gbk", "abc", "latin1", "utf8mb4_bin", 1},
+		{"abc", "latin1", "ABC", "gbk", "utf8mb4_general_ci", 0},
+		// Binary strings are compared byte by byte.
+		{"A", "binary", "a", "utf8mb4", "utf8mb4_general_ci", -1},
+		{"a", "latin1", "A", "binary", "utf8mb4_general_ci", 1},
+	}
+	for i, tt := range tbl {
+		lhs, rhs := NewStringDatum(tt.lhs), NewStringDatum(tt.rhs)
+		ret, err := lhs.CompareCrossCharset(sc, &rhs, tt.lhsCharset, tt.rhsCharset, collate.GetCollator(tt.coll))
+		require.NoError(t, err, "%d", i)
+		require.Equal(t, tt.ret, ret, "%d", i)
+	}
+
+	// A character which can't be represented in the charset of the lhs.
+	tbl2 := []struct {
+		lhs        string
+		lhsCharset string
+		rhs        string
+		rhsCharset string
+	}{
+		{"abc", "latin1", "中文", "gbk"},
+		{"abc", "latin1", "日本", "gbk"},
+	}
+	for i, tt := range tbl2 {
+		lhs, rhs := NewStringDatum(tt.lhs), NewStringDatum(tt.rhs)
+		_, err := lhs.CompareCrossCharset(sc, &rhs, tt.lhsCharset, tt.rhsCharset, collate.GetCollator("utf8mb4_bin"))
+		require.Error(t, err, "%d", i)
+		require.Contains(t, err.Error(), "Invalid "+tt.lhsCharset+" character string", "%d", i)
+	}
+}
+
+func TestCompareGeneralCI(t *testing.T) {
+	collate.SetNewCollationEnabledForTest(true)
+	defer collate.SetNewCollationEnabledForTest(false)
+
+	sc := new(stmtctx.StatementContext)
+	ci := collate.GetCollator("utf8mb4_general_ci")
+	long := strings.Repeat("aB", 512)
+	tbl := []struct {
+		lhs string
+		rhs string
+		ret int
+	}{
+		{long + "a", long + "b", -1},
+		{long + "c", long + "B", 1},
+		{long + "a", strings.ToUpper(long) + "A", 0},
+		{long + "a ", long + "A", 0},
+		{long, long + "a", -1},
+		{"ß", "s", 0},
+		{"Ä", "a", 0},
+	}
+	for i, tt := range tbl {
+		lhs, rhs := NewStringDatum(tt.lhs), NewStringDatum(tt.rhs)
+		ret, err := lhs.Compare(sc, &rhs, ci)
+		require.NoError(t, err)
+		require.Equal(t, tt.ret, ret, "%d", i)
+		require.Equal(t, tt.ret, ci.Compare(tt.lhs, tt.rhs), "%d", i)
+
+		ret, err = rhs.Compare(sc, &lhs, ci)
+		require.NoError(t, err)
+		require.Equal(t, -tt.ret, ret, "%d", i)
+	}
+}
+
+func BenchmarkCompareGeneralCI(b *testing.B) {
+	collate.SetNewCollationEnabledForTest(true)
+	defer collate.SetNewCollationEnabledForTest(false)
+
+	sc := new(stmtctx.StatementContext)
+	ci := collate.GetCollator("utf8mb4_general_ci")
+	long := strings.Repeat("aBcDeFgH", 128)
+	lhs, rhs := NewStringDatum(long+"a"), NewStringDatum(long+"B")
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := lhs.Compare(sc, &rhs, ci); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestCompareSharedString(t *testing.T) {
+	collate.SetNewCollationEnabledForTest(true)
+	defer collate.SetNewCollationEnabledForTest(false)
+
+	sc := new(stmtctx.StatementContext)
+	strs := []string{"a", "A ", "ß", "中文 ", strings.Repeat("aBcD", 64)}
+	for _, coll := range []string{"utf8mb4_bin", "utf8mb4_general_ci", "utf8mb4_unicode_ci", "binary"} {
+		collator := collate.GetCollator(coll)
+		for _, str := range strs {
+			// Both datums share the backing bytes of str, or one of them is a copy, or a prefix.
+			shared, other := NewStringDatum(str), NewStringDatum(str)
+			copied := NewBytesDatum([]byte(str))
+			prefix := NewStringDatum(str[:len(str)-1])
+			require.True(t, sharesBacking(shared.b, other.GetString()))
+			require.False(t, sharesBacking(shared.b, copied.GetString()))
+			require.False(t, sharesBacking(shared.b, prefix.GetString()))
+			for _, rhs := range []Datum{other, copied, prefix} {
+				ret, err := shared.Compare(sc, &rhs, collator)
+				require.NoError(t, err)
+				require.Equal(t, collator.Compare(shared.GetString(), rhs.GetString()), ret, "%s %q %q", coll, str, rhs.GetString())
+			}
+		}
+	}
+	require.False(t, sharesBacking(nil, ""))
+}
+
+func BenchmarkCompareSharedString(b *testing.B) {
+	collate.SetNewCollationEnabledForTest(true)
+	defer collate.SetNewCollationEnabledForTest(false)
+
+	sc := new(stmtctx.StatementContext)
+	ci := collate.GetCollator("utf8mb4_unicode_ci")
+	long := strings.Repeat("aBcDeFgH", 128)
+	lhs, shared, copied := NewStringDatum(long), NewStringDatum(long), NewBytesDatum([]byte(long))
+	for name, rhs := range map[string]Datum{"shared": shared, "copied": copied} {
+		rhs := rhs
+		b.Run(name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				if _, err := lhs.Compare(sc, &rhs, ci); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func TestHash64WithCollation(t *testing.T) {
+	collate.SetNewCollationEnabledForTest(true)
+	defer collate.SetNewCollationEnabledForTest(false)
+
+	sc := new(stmtctx.StatementContext)
+	strs := []string{"a", "A", "a ", "b", "B  ", "ß", "s", "S", "Ä", "中文", "中文 ", "", " "}
+	for _, coll := range []string{"utf8mb4_bin", "utf8mb4_general_ci", "utf8mb4_unicode_ci", "binary"} {
+		collator := collate.GetCollator(coll)
+		for _, a := range strs {
+			for _, b := range strs {
+				da, db := NewStringDatum(a), NewStringDatum(b)
+				cmp, err := da.Compare(sc, &db, collator)
+				require.NoError(t, err)
+				ha, err := da.Hash64(sc, collator)
+				require.NoError(t, err)
+				hb, err := db.Hash64(sc, collator)
+				require.NoError(t, err)
+				if cmp == 0 {
+					require.Equal(t, ha, hb, "%s: %q %q", coll, a, b)
+				} else {
+					require.NotEqual(t, ha, hb, "%s: %q %q", coll, a, b)
+				}
+			}
+		}
+	}
+
+	ci := collate.GetCollator("utf8mb4_general_ci")
+	elems := []string{"active", "inactive"}
+	e, err := ParseEnumNameWithCollation(elems, "ACTIVE", ci)
+	require.NoError(t, err)
+	ds := []Datum{NewStringDatum("ACTIVE"), NewMysqlEnumDatum(e), NewMysqlSetDatum(Set{Name: "Active", Value: 1}, "utf8mb4_general_ci")}
+	h, err := ds[0].Hash64(sc, ci)
+	require.NoError(t, err)
+	for _, d := range ds[1:] {
+		cmp, err := ds[0].Compare(sc, &d, ci)
+		require.NoError(t, err)
+		require.Equal(t, 0, cmp)
+		h1, err := d.Hash64(sc, ci)
+		require.NoError(t, err)
+		require.Equal(t, h, h1, "%v", d)
+	}
+}
+
+func TestVecCompareString(t *testing.T) {
+	collate.SetNewCollationEnabledForTest(true)
+	defer collate.SetNewCollationEnabledForTest(false)
+
+	strs := []string{"", " ", "a", "a ", "a  ", "A", "b", "B ", "ab", "aB", "ß", "s", "Ä", "ä", "中文", "中文 ", "\t", "a\t"}
+	x := make([]string, 0, len(strs)*len(strs))
+	y := make([]string, 0, len(strs)*len(strs))
+	for _, lhs := range strs {
+		for _, rhs := range strs {
+			x = append(x, lhs)
+			y = append(y, rhs)
+		}
+	}
+	res := make([]int64, len(x))
+	for _, coll := range []string{"binary", "utf8mb4_bin", "latin1_bin", "utf8mb4_general_ci", "utf8mb4_unicode_ci"} {
+		collator := collate.GetCollator(coll)
+		VecCompareString(collator, x, y, res)
+		for i := range x {
+			require.Equal(t, int64(collator.Compare(x[i], y[i])), res[i], "%s %q %q", coll, x[i], y[i])
+		}
+	}
+
+	// PAD SPACE collations ignore the trailing spaces, while the binary one doesn't.
+	VecCompareString(collate.GetCollator("utf8mb4_general_ci"), []string{"a ", "A", "a\t"}, []string{"A", "a  ", "a"}, res)
+	require.Equal(t, []int64{0, 0, 1}, res[:3])
+	VecCompareString(collate.GetCollator("binary"), []string{"a ", "A", "a"}, []string{"a", "a", "a"}, res)
+	require.Equal(t, []int64{1, -1, 0}, res[:3])
+}
+
+func BenchmarkVecCompareString(b *testing.B) {
+	collate.SetNewCollationEnabledForTest(true)
+	defer collate.SetNewCollationEnabledForTest(false)
+
+	const n = 1024
+	x, y := make([]string, n), make([]string, n)
+	for i := 0; i < n; i++ {
+		x[i] = strings.Repeat("aBcD", i%16) + strconv.Itoa(i)
+		if i%2 == 0 {
+			y[i] = x[i]
+		} else {
+			y[i] = strings.ToUpper(x[i]) + " "
+		}
+	}
+	res := make([]int64, n)
+	for _, coll := range []string{"utf8mb4_bin", "utf8mb4_general_ci"} {
+		collator := collate.GetCollator(coll)
+		b.Run(coll+"/Compare", func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				for j := range x {
+					res[j] = int64(collator.Compare(x[j], y[j]))
+				}
+			}
+		})
+		b.Run(coll+"/VecCompareString", func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				VecCompareString(collator, x, y, res)
+			}
+		})
+	}
+}
+
+func TestCompareWithCollationInfo(t *testing.T) {
+	sc := new(stmtctx.StatementContext)
+	a, b := NewStringDatum("a"), NewStringDatum("A")
+	i := NewIntDatum(1)
+
+	ret, coll, err := a.CompareWithCollationInfo(sc, &b, "utf8mb4_general_ci")
+	require.NoError(t, err)
+	require.Equal(t, 1, ret)
+	require.Equal(t, "binary", coll)
+
+	collate.SetNewCollationEnabledForTest(true)
+	defer collate.SetNewCollationEnabledForTest(false)
+
+	ret, coll, err = a.CompareWithCollationInfo(sc, &b, "utf8mb4_general_ci")
+	require.NoError(t, err)
+	require.Equal(t, 0, ret)
+	require.Equal(t, "utf8mb4_general_ci", coll)
+
+	_, coll, err = i.CompareWithCollationInfo(sc, &a, "utf8mb4_general_ci")
+	require.NoError(t, err)
+	require.Equal(t, "binary", coll)
+	_, coll, err = i.CompareWithCollationInfo(sc, &i, "utf8mb4_general_ci")
+	require.NoError(t, err)
+	require.Equal(t, "binary", coll)
+
+	// BIT and ENUM are compared numerically in both directions.
+	bit := NewMysqlBitDatum(NewBinaryLiteralFromUint(1, -1))
+	enum := NewMysqlEnumDatum(Enum{Name: "a", Value: 1})
+	ret, coll, err = bit.CompareWithCollationInfo(sc, &enum, "utf8mb4_general_ci")
+	require.NoError(t, err)
+	require.Equal(t, 0, ret)
+	require.Equal(t, "binary", coll)
+	ret, coll, err = enum.CompareWithCollationInfo(sc, &bit, "utf8mb4_general_ci")
+	require.NoError(t, err)
+	require.Equal(t, 0, ret)
+	require.Equal(t, "binary", coll)
+
+	// A string is compared with an ENUM by its name.
+	ret, coll, err = b.CompareWithCollationInfo(sc, &enum, "utf8mb4_general_ci")
+	require.NoError(t, err)
+	require.Equal(t, 0, ret)
+	require.Equal(t, "utf8mb4_general_ci", coll)
+}
@@ -16,12 +16,18 @@ package types
 
 import (
 	"math"
+	"math/rand"
+	"os"
+	"sort"
+	"strconv"
 	"testing"
 	"time"
 
 	"github.com/pingcap/tidb/parser/mysql"
 	"github.com/pingcap/tidb/sessionctx/stmtctx"
+	"github.com/pingcap/tidb/types/json"
 	"github.com/pingcap/tidb/util/collate"
+	"github.com/pingcap/tidb/util/israce"
 	"github.com/stretchr/testify/require"
 )
 
@@ -131,6 +137,9 @@ func TestCompare(t *testing.T) {
 		{Set{Name: "a", Value: 1}, NewBinaryLiteralFromUint(1, -1), 0},
 		{Set{Name: "a", Value: 1}, Enum{Name: "a", Value: 1}, 0},
 		{Set{Name: "a", Value: 1}, Set{Name: "a", Value: 1}, 0},
+		// Sets are compared by the bitmask rather than the Name.
+		{Set{Name: "b,a", Value: 3}, Set{Name: "a,b", Value: 3}, 0},
+		{Set{Name: "b", Value: 2}, Set{Name: "a,c", Value: 5}, -1},
 
 		{"hello", NewDecFromInt(0), 0}, // compatible with MySQL.
 		{NewDecFromInt(0), "hello", 0},
@@ -185,6 +194,177 @@ func TestCompareDatum(t *testing.T) {
 	}
 }
 
+func TestCompareSentinel(t *testing.T) {
+	t.Parallel()
+
+	maxVal, minNotNull := MaxValueDatum(), MinNotNullDatum()
+	require.True(t, maxVal.IsMaxValue())
+	require.False(t, maxVal.IsMinNotNull())
+	require.True(t, minNotNull.IsMinNotNull())
+	require.False(t, minNotNull.IsMaxValue())
+	require.False(t, (&Datum{}).IsMaxValue())
+
+	cmpTbl := []struct {
+		lhs Datum
+		rhs Datum
+		ret int // 0, 1, -1
+	}{
+		{MaxValueDatum(), MaxValueDatum(), 0},
+		{MinNotNullDatum(), MinNotNullDatum(), 0},
+		{MinNotNullDatum(), Datum{}, 1},
+		{MaxValueDatum(), Datum{}, 1},
+		{MaxValueDatum(), MinNotNullDatum(), 1},
+		{MaxValueDatum(), NewIntDatum(math.MaxInt64), 1},
+		{MaxValueDatum(), NewStringDatum("\xff\xff"), 1},
+		{MinNotNullDatum(), NewIntDatum(math.MinInt64), -1},
+		{MinNotNullDatum(), NewStringDatum(""), -1},
+		{MaxValueDatum(), NewJSONDatum(json.CreateBinary("a")), 1},
+		{MinNotNullDatum(), NewJSONDatum(json.CreateBinary(nil)), -1},
+	}
+	sc := new(stmtctx.StatementContext)
+	for i, tt := range cmpTbl {
+		ret, err := tt.lhs.Compare(sc, &tt.rhs, collate.GetBinaryCollator())
+		require.NoError(t, err)
+		require.Equal(t, tt.ret, ret, "%d %v %v", i, tt.lhs, tt.rhs)
+
+		ret, err = tt.rhs.Compare(sc, &tt.lhs, collate.GetBinaryCollator())
+		require.NoError(t, err)
+		require.Equal(t, -tt.ret, ret, "%d %v %v", i, tt.lhs, tt.rhs)
+	}
+}
+
+func TestDatumAdjacent(t *testing.T) {
+	t.Parallel()
+	sc := new(stmtctx.StatementContext)
+	intTp := NewFieldType(mysql.TypeLonglong)
+	tbl := []struct {
+		lhs Datum
+		rhs Datum
+		tp  *FieldType
+		ret bool
+	}{
+		{NewIntDatum(5), NewIntDatum(6), intTp, true},
+		{NewIntDatum(-1), NewUintDatum(0), intTp, true},
+		{NewIntDatum(math.MaxInt64), NewUintDatum(math.MaxInt64 + 1), intTp, true},
+		{NewUintDatum(7), NewUintDatum(8), intTp, true},
+		{NewIntDatum(5), NewIntDatum(5), intTp, false},
+		{NewIntDatum(5), NewIntDatum(7), intTp, false},
+		{NewIntDatum(6), NewIntDatum(5), intTp, false},
+		{NewUintDatum(math.MaxUint64), NewUintDatum(0), intTp, false},
+		{NewFloat64Datum(1), NewFloat64Datum(2), NewFieldType(mysql.TypeDouble), false},
+		{NewDecimalDatum(NewDecFromInt(1)), NewDecimalDatum(NewDecFromInt(2)), NewFieldType(mysql.TypeNewDecimal), false},
+		{NewStringDatum("a"), NewStringDatum("b"), NewFieldType(mysql.TypeVarchar), false},
+	}
+	for i, tt := range tbl {
+		ret, err := tt.lhs.Adjacent(sc, &tt.rhs, collate.GetBinaryCollator(), tt.tp)
+		require.NoError(t, err)
+		require.Equal(t, tt.ret, ret, "%d %v %v", i, tt.lhs, tt.rhs)
+	}
+}
+
+func TestCompareStable(t *testing.T) {
+	t.Parallel()
+	sc := new(stmtctx.StatementContext)
+	tbl := []struct {
+		lhs Datum
+		rhs Datum
+		ret int
+	}{
+		{NewIntDatum(1), NewStringDatum("1"), -1},
+		{NewIntDatum(1), NewFloat64Datum(1), -1},
+		{NewIntDatum(1), NewDecimalDatum(NewDecFromInt(1)), -1},
+		{NewStringDatum("1"), NewDecimalDatum(NewDecFromInt(1)), -1},
+		{NewUintDatum(1), NewIntDatum(1), 1},
+		{NewIntDatum(1), NewIntDatum(1), 0},
+		{NewIntDatum(1), NewStringDatum("2"), -1},
+		{NewIntDatum(2), NewStringDatum("1"), 1},
+	}
+	for i, tt := range tbl {
+		ret, err := tt.lhs.CompareStable(sc, &tt.rhs, collate.GetBinaryCollator())
+		require.NoError(t, err)
+		require.Equal(t, tt.ret, ret, "%d %v %v", i, tt.lhs, tt.rhs)
+
+		ret, err = tt.rhs.CompareStable(sc, &tt.lhs, collate.GetBinaryCollator())
+		require.NoError(t, err)
+		require.Equal(t, -tt.ret, ret, "%d %v %v", i, tt.lhs, tt.rhs)
+	}
+}
+
+func TestIsComparableWith(t *testing.T) {
+	t.Parallel()
+	sc := new(stmtctx.StatementContext)
+	now := NewTimeDatum(NewTime(FromDate(2023, 1, 1, 0, 0, 0, 0), mysql.TypeDatetime, DefaultFsp))
+	var null, raw Datum
+	maxValue := MaxValueDatum()
+	raw.k = KindRaw
+	tbl := []struct {
+		lhs             Datum
+		rhs             Datum
+		comparable      bool
+		needsConversion bool
+	}{
+		{NewIntDatum(1), NewIntDatum(2), true, false},
+		{NewIntDatum(1), NewUintDatum(2), true, false},
+		{NewFloat32Datum(1), NewFloat64Datum(2), true, false},
+		{NewStringDatum("a"), NewBytesDatum([]byte("b")), true, false},
+		{now, now, true, false},
+		{NewIntDatum(1), NewFloat64Datum(1), true, true},
+		{NewIntDatum(1), NewDecimalDatum(NewDecFromInt(1)), true, true},
+		{NewIntDatum(1), NewStringDatum("1"), true, true},
+		{NewDurationDatum(ZeroDuration), NewStringDatum("00:00:01"), true, true},
+		{now, NewStringDatum("2023-01-01"), true, true},
+		{now, NewStringDatum("not a time"), true, true},
+		{NewMysqlBitDatum(NewBinaryLiteralFromUint(1, -1)), NewIntDatum(1), true, true},
+		{NewJSONDatum(json.CreateBinary(int64(1))), NewIntDatum(1), true, true},
+		{null, NewStringDatum("a"), true, false},
+		{maxValue, now, true, false},
+		{raw, NewIntDatum(1), false, false},
+		{raw, null, false, false},
+	}
+	for i, tt := range tbl {
+		comparable, needsConversion := tt.lhs.IsComparableWith(&tt.rhs)
+		require.Equal(t, tt.comparable, comparable, "%d %v %v", i, tt.lhs, tt.rhs)
+		require.Equal(t, tt.needsConversion, needsConversion, "%d %v %v", i, tt.lhs, tt.rhs)
+		comparable, needsConversion = tt.rhs.IsComparableWith(&tt.lhs)
+		require.Equal(t, tt.comparable, comparable, "%d %v %v", i, tt.lhs, tt.rhs)
+		require.Equal(t, tt.needsConversion, needsConversion, "%d %v %v", i, tt.lhs, tt.rhs)
+
+		// Comparing without conversion never fails.
+		if tt.comparable && !tt.needsConversion {
+			_, err := tt.lhs.Compare(sc, &tt.rhs, collate.GetBinaryCollator())
+			require.NoError(t, err, "%d %v %v", i, tt.lhs, tt.rhs)
+		}
+	}
+
+	// A malformed string is comparable with a Time, but the conversion fails.
+	str := NewStringDatum("not a time")
+	_, err := now.Compare(sc, &str, collate.GetBinaryCollator())
+	require.Error(t, err)
+}
+
+func TestCompareZeroTemporal(t *testing.T) {
+	t.Parallel()
+
+	null := Datum{}
+	zero := NewTimeDatum(NewTime(ZeroCoreTime, mysql.TypeDatetime, 0))
+	dt := NewTimeDatum(NewTime(FromDate(2021, 1, 1, 0, 0, 0, 0), mysql.TypeDatetime, 0))
+	require.False(t, null.IsZeroTemporal())
+	require.True(t, zero.IsZeroTemporal())
+	require.False(t, dt.IsZeroTemporal())
+	intDatum := NewIntDatum(0)
+	require.False(t, intDatum.IsZeroTemporal())
+
+	sc := new(stmtctx.StatementContext)
+	ordered := []Datum{null, zero, dt}
+	for i := range ordered {
+		for j := range ordered {
+			ret, err := ordered[i].Compare(sc, &ordered[j], collate.GetBinaryCollator())
+			require.NoError(t, err)
+			require.Equal(t, CompareInt64(int64(i), int64(j)), ret, "%d %d", i, j)
+		}
+	}
+}
+
 func TestVecCompareIntAndUint(t *testing.T) {
 	t.Parallel()
 
@@ -263,3 +443,649 @@ func TestVecCompareIntAndUint(t *testing.T) {
 		}
 	}
 }
+
+// randomCompareDatum generates a datum whose comparisons with other generated datums are exact,
+// so that the properties checked in TestCompareRandomized must hold.
+func randomCompareDatum(r *rand.Rand) Datum {
+	switch r.Intn(7) {
+	case 0:
+		return NewIntDatum(r.Int63n(2001) - 1000)
+	case 1:
+		return NewUintDatum(uint64(r.Int63n(1001)))
+	case 2:
+		return NewFloat64Datum(float64(r.Int63n(8001)-4000) / 4)
+	case 3:
+		dec := new(MyDecimal)
+		if err := dec.FromFloat64(float64(r.Int63n(8001)-4000) / 4); err != nil {
+			panic(err)
+		}
+		return NewDecimalDatum(dec)
+	case 4:
+		return NewDurationDatum(Duration{Duration: time.Duration(r.Int63n(2001)-1000) * time.Second})
+	case 5:
+		ct := FromDate(2000+r.Intn(30), 1+r.Intn(12), 1+r.Intn(28), r.Intn(24), r.Intn(60), r.Intn(60), 0)
+		return NewTimeDatum(NewTime(ct, mysql.TypeDatetime, 0))
+	default:
+		return NewStringDatum(strconv.FormatInt(r.Int63n(2001)-1000, 10))
+	}
+}
+
+// isDurationDecimalPair reports whether a and b are a duration and a decimal. A duration is
+// converted to HHMMSS when compared against a decimal, but to seconds the other way around.
+// TODO: remove it once the two directions are made consistent.
+func isDurationDecimalPair(a, b *Datum) bool {
+	return (a.k == KindMysqlDuration && b.k == KindMysqlDecimal) || (a.k == KindMysqlDecimal && b.k == KindMysqlDuration)
+}
+
+func isTemporal(d *Datum) bool {
+	return d.k == KindMysqlTime || d.k == KindMysqlDuration
+}
+
+func TestVecCompareFF(t *testing.T) {
+	t.Parallel()
+	nan, inf := math.NaN(), math.Inf(1)
+	negZero := math.Copysign(0, -1)
+	lhs := []float64{1, 2, 2, -1.5, 0, negZero, -inf, inf, math.MaxFloat64, math.SmallestNonzeroFloat64, nan, nan, nan, 1, -inf}
+	rhs := []float64{2, 1, 2, -1.5, negZero, 0, inf, inf, inf, 0, nan, 0, -inf, nan, nan}
+	// Like CompareFloat64, a comparison involving NaN returns 1.
+	ret := []int64{-1, 1, 0, 0, 0, 0, -1, 0, -1, 1, 1, 1, 1, 1, 1}
+	res := make([]int64, len(lhs))
+	VecCompareFF(lhs, rhs, res)
+	require.Equal(t, ret, res)
+	for i := range lhs {
+		require.Equal(t, int64(CompareFloat64(lhs[i], rhs[i])), res[i], "%v %v", lhs[i], rhs[i])
+	}
+}
+
+func TestVecCompareDD(t *testing.T) {
+	t.Parallel()
+	strs := []string{"0", "-0.000", "1", "1.000", "-1", "0.5", "-0.5", "999999999", "1000000000",
+		"123456789.987654321", "123456789.987654322", "-123456789.987654321", "0.000000001", "0.0000000009"}
+	var lhs, rhs []MyDecimal
+	for _, a := range strs {
+		for _, b := range strs {
+			lhs = append(lhs, *NewDecFromStringForTest(a))
+			rhs = append(rhs, *NewDecFromStringForTest(b))
+		}
+	}
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 100; i++ {
+		lhs = append(lhs, *NewDecFromFloatForTest(r.NormFloat64() * 1e6))
+		rhs = append(rhs, *NewDecFromFloatForTest(r.NormFloat64() * 1e6))
+	}
+	res := make([]int64, len(lhs))
+	VecCompareDD(lhs, rhs, res)
+	for i := range lhs {
+		require.Equal(t, int64(lhs[i].Compare(&rhs[i])), res[i], "%s %s", lhs[i].String(), rhs[i].String())
+	}
+}
+
+func TestCompareJSONNull(t *testing.T) {
+	t.Parallel()
+	sc := new(stmtctx.StatementContext)
+	jsonNull := NewJSONDatum(json.CreateBinary(nil))
+	sqlNull := Datum{}
+	tests := []struct {
+		lhs Datum
+		rhs Datum
+		ret int
+	}{
+		// A JSON null is a concrete value, which sorts before other JSON scalars like MySQL does.
+		{jsonNull, jsonNull, 0},
+		{jsonNull, NewJSONDatum(json.CreateBinary(int64(-1))), -1},
+		{jsonNull, NewJSONDatum(json.CreateBinary("")), -1},
+		{jsonNull, NewIntDatum(0), -1},
+		// SQL NULL is less than anything, including a JSON null.
+		{sqlNull, jsonNull, -1},
+		{sqlNull, NewJSONDatum(json.CreateBinary(int64(-1))), -1},
+		{sqlNull, NewIntDatum(0), -1},
+		{sqlNull, sqlNull, 0},
+	}
+	for i, tt := range tests {
+		ret, err := tt.lhs.Compare(sc, &tt.rhs, collate.GetBinaryCollator())
+		require.NoError(t, err)
+		require.Equal(t, tt.ret, ret, "%d %v %v", i, tt.lhs, tt.rhs)
+
+		ret, err = tt.rhs.Compare(sc, &tt.lhs, collate.GetBinaryCollator())
+		require.NoError(t, err)
+		require.Equal(t, -tt.ret, ret, "%d %v %v", i, tt.lhs, tt.rhs)
+	}
+	require.False(t, jsonNull.IsNull())
+}
+
+func TestCompareGroupBy(t *testing.T) {
+	t.Parallel()
+	sc := new(stmtctx.StatementContext)
+	// NULLs group together whatever value the datum held before it was set to NULL.
+	nulls := []Datum{{}, NewDatum(nil), NewIntDatum(1), NewStringDatum("a"), NewCollationStringDatum("b", "utf8mb4_general_ci")}
+	for i := 2; i < len(nulls); i++ {
+		nulls[i].SetNull()
+	}
+	values := []Datum{
+		NewIntDatum(0), NewUintDatum(0), NewFloat64Datum(0), NewStringDatum(""), NewBytesDatum(nil),
+		NewDecimalDatum(NewDecFromInt(0)), NewDurationDatum(ZeroDuration), NewTimeDatum(ZeroDatetime),
+		NewMysqlEnumDatum(Enum{}), NewMysqlSetDatum(Set{}, ""), NewBinaryLiteralDatum(nil),
+		NewJSONDatum(json.CreateBinary(nil)), MinNotNullDatum(), MaxValueDatum(),
+	}
+	for _, collator := range []collate.Collator{collate.GetBinaryCollator(), collate.GetCollator("utf8mb4_general_ci")} {
+		for i := range nulls {
+			for j := range nulls {
+				ret, err := nulls[i].CompareGroupBy(sc, &nulls[j], collator)
+				require.NoError(t, err)
+				require.Equal(t, 0, ret, "%d %d", i, j)
+
+				// Unlike GROUP BY, comparing NULLs in WHERE is unknown.
+				_, isNull, err := CompareNullable(sc, &nulls[i], &nulls[j], collator)
+				require.NoError(t, err)
+				require.True(t, isNull)
+			}
+			// A NULL sorts before any other value, the same as Compare.
+			for j := range values {
+				ret, err := nulls[i].CompareGroupBy(sc, &values[j], collator)
+				require.NoError(t, err)
+				require.Equal(t, -1, ret, "%d %v", i, values[j])
+				expected, err := nulls[i].Compare(sc, &values[j], collator)
+				require.NoError(t, err)
+				require.Equal(t, expected, ret)
+
+				ret, err = values[j].CompareGroupBy(sc, &nulls[i], collator)
+				require.NoError(t, err)
+				require.Equal(t, 1, ret, "%d %v", i, values[j])
+			}
+		}
+		// Values compare like Compare.
+		for i := range values {
+			for j := range values {
+				expected, expectedErr := values[i].Compare(sc, &values[j], collator)
+				ret, err := values[i].CompareGroupBy(sc, &values[j], collator)
+				require.Equal(t, expectedErr == nil, err == nil)
+				require.Equal(t, expected, ret, "%v %v", values[i], values[j])
+			}
+		}
+	}
+}
+
+func TestCompareSameKindIntegers(t *testing.T) {
+	t.Parallel()
+	sc := new(stmtctx.StatementContext)
+	ints := []int64{math.MinInt64, math.MinInt64 + 1, -1, 0, 1, math.MaxInt64 - 1, math.MaxInt64}
+	uints := []uint64{0, 1, math.MaxInt64, math.MaxInt64 + 1, math.MaxUint64 - 1, math.MaxUint64}
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 100; i++ {
+		ints = append(ints, r.Int63()-r.Int63())
+		uints = append(uints, r.Uint64())
+	}
+	// The fast path must agree with the generic comparison.
+	for _, x := range ints {
+		for _, y := range ints {
+			lhs, rhs := NewIntDatum(x), NewIntDatum(y)
+			ret, err := lhs.Compare(sc, &rhs, collate.GetBinaryCollator())
+			require.NoError(t, err)
+			expected, err := lhs.compareInt64(sc, y)
+			require.NoError(t, err)
+			require.Equal(t, expected, ret, "%d %d", x, y)
+			require.Equal(t, ret, lhs.CompareInt64(y), "%d %d", x, y)
+		}
+	}
+	for _, x := range uints {
+		for _, y := range uints {
+			lhs, rhs := NewUintDatum(x), NewUintDatum(y)
+			ret, err := lhs.Compare(sc, &rhs, collate.GetBinaryCollator())
+			require.NoError(t, err)
+			expected, err := lhs.compareUint64(sc, y)
+			require.NoError(t, err)
+			require.Equal(t, expected, ret, "%d %d", x, y)
+			require.Equal(t, ret, lhs.CompareUint64(y), "%d %d", x, y)
+		}
+	}
+
+	if israce.RaceEnabled {
+		i, u := NewIntDatum(1), NewUintDatum(1)
+		require.Panics(t, func() { u.CompareInt64(1) })
+		require.Panics(t, func() { i.CompareUint64(1) })
+	}
+}
+
+func TestCompareRandomized(t *testing.T) {
+	t.Parallel()
+	// Set the compare_seed environment variable to reproduce a counterexample.
+	seed := int64(1)
+	if s := os.Getenv("compare_seed"); s != "" {
+		var err error
+		seed, err = strconv.ParseInt(s, 10, 64)
+		require.NoError(t, err)
+	}
+	r := rand.New(rand.NewSource(seed))
+	sc := new(stmtctx.StatementContext)
+	sc.IgnoreTruncate = true
+	cmp := func(a, b *Datum) int {
+		ret, err := a.Compare(sc, b, collate.GetBinaryCollator())
+		require.NoError(t, err, "seed %d: %v %v", seed, a, b)
+		return ret
+	}
+
+	for i := 0; i < 1000; i++ {
+		a, b := randomCompareDatum(r), randomCompareDatum(r)
+		// A generated numeric string is not always a valid datetime or duration.
+		if isDurationDecimalPair(&a, &b) || (a.k == KindString && isTemporal(&b)) || (b.k == KindString && isTemporal(&a)) {
+			continue
+		}
+		require.Equal(t, cmp(&a, &b), -cmp(&b, &a), "seed %d: %v %v", seed, a, b)
+	}
+
+	// Strings compare with each other lexicographically rather than numerically, so they are
+	// left out of the transitivity check.
+	for i := 0; i < 1000; i++ {
+		a, b, c := randomCompareDatum(r), randomCompareDatum(r), randomCompareDatum(r)
+		if a.k == KindString || b.k == KindString || c.k == KindString ||
+			isDurationDecimalPair(&a, &b) || isDurationDecimalPair(&b, &c) || isDurationDecimalPair(&a, &c) {
+			continue
+		}
+		ab, bc, ac := cmp(&a, &b), cmp(&b, &c), cmp(&a, &c)
+		if ab <= 0 && bc <= 0 {
+			require.LessOrEqual(t, ac, 0, "seed %d: %v %v %v", seed, a, b, c)
+		}
+		if ab >= 0 && bc >= 0 {
+			require.GreaterOrEqual(t, ac, 0, "seed %d: %v %v %v", seed, a, b, c)
+		}
+		if ab == 0 && bc == 0 {
+			require.Equal(t, 0, ac, "seed %d: %v %v %v", seed, a, b, c)
+		}
+	}
+}
+
+func TestCompareNullable(t *testing.T) {
+	t.Parallel()
+	sc := new(stmtctx.StatementContext)
+	bin := collate.GetBinaryCollator()
+	var null Datum
+	one, two := NewIntDatum(1), NewIntDatum(2)
+	minNotNull, maxValue := MinNotNullDatum(), MaxValueDatum()
+
+	// The truth tables of a = b and a < b, where nil stands for unknown.
+	yes, no := true, false
+	tbl := []struct {
+		a, b Datum
+		eq   *bool
+		lt   *bool
+	}{
+		{one, one, &yes, &no},
+		{one, two, &no, &yes},
+		{two, one, &no, &no},
+		{null, one, nil, nil},
+		{one, null, nil, nil},
+		{null, null, nil, nil},
+		{null, NewStringDatum(""), nil, nil},
+		{minNotNull, one, &no, &yes},
+		{one, maxValue, &no, &yes},
+		{minNotNull, null, nil, nil},
+	}
+	for i, tt := range tbl {
+		cmp, isNull, err := CompareNullable(sc, &tt.a, &tt.b, bin)
+		require.NoError(t, err)
+		require.Equal(t, tt.eq == nil, isNull, "%d", i)
+		if tt.eq == nil {
+			require.Nil(t, tt.lt)
+			// Only IS NULL tells a NULL operand apart.
+			require.True(t, tt.a.IsNull() || tt.b.IsNull(), "%d", i)
+			continue
+		}
+		require.Equal(t, *tt.eq, cmp == 0, "%d", i)
+		require.Equal(t, *tt.lt, cmp < 0, "%d", i)
+
+		// Without a NULL operand it is the same as Compare.
+		expected, err := tt.a.Compare(sc, &tt.b, bin)
+		require.NoError(t, err)
+		require.Equal(t, expected, cmp, "%d", i)
+	}
+
+	// A JSON null is a value, so comparing it is not unknown and IS NULL is false.
+	jsonNull := NewJSONDatum(json.CreateBinary(nil))
+	require.False(t, jsonNull.IsNull())
+	cmp, isNull, err := CompareNullable(sc, &jsonNull, &jsonNull, bin)
+	require.NoError(t, err)
+	require.False(t, isNull)
+	require.Equal(t, 0, cmp)
+
+	// An error from Compare is returned as is.
+	now := NewTimeDatum(NewTime(FromDate(2023, 1, 1, 0, 0, 0, 0), mysql.TypeDatetime, DefaultFsp))
+	str := NewStringDatum("not a time")
+	_, isNull, err = CompareNullable(sc, &now, &str, bin)
+	require.Error(t, err)
+	require.False(t, isNull)
+}
+
+func TestHash64(t *testing.T) {
+	t.Parallel()
+	sc := new(stmtctx.StatementContext)
+	bin := collate.GetBinaryCollator()
+	jsonDatum := func(s string) Datum {
+		j, err := json.ParseBinaryFromString(s)
+		require.NoError(t, err)
+		return NewJSONDatum(j)
+	}
+	ct := FromDate(2023, 1, 2, 3, 4, 5, 600000)
+	// Each group compares equal, and so must hash equal.
+	groups := [][]Datum{
+		{NewIntDatum(1), NewUintDatum(1), NewFloat64Datum(1), NewFloat32Datum(1), NewDecimalDatum(NewDecFromStringForTest("1.00"))},
+		{NewIntDatum(0), NewFloat64Datum(math.Copysign(0, -1)), NewDecimalDatum(NewDecFromStringForTest("-0.000"))},
+		{NewFloat64Datum(-2.5), NewDecimalDatum(NewDecFromStringForTest("-2.50"))},
+		{NewStringDatum("a"), NewBytesDatum([]byte("a")), NewBinaryLiteralDatum(BinaryLiteral{0, 0x61}),
+			NewMysqlBitDatum(BinaryLiteral{0x61}), NewMysqlEnumDatum(Enum{Name: "a", Value: 2})},
+		{NewDurationDatum(Duration{Duration: time.Second, Fsp: 0}), NewDurationDatum(Duration{Duration: time.Second, Fsp: 6})},
+		{NewTimeDatum(NewTime(ct, mysql.TypeDatetime, 6)), NewTimeDatum(NewTime(ct, mysql.TypeTimestamp, 6))},
+		{NewMysqlSetDatum(Set{Name: "a,c", Value: 5}, ""), NewMysqlSetDatum(Set{Name: "c,a", Value: 5}, "")},
+		{jsonDatum(`3`), jsonDatum(`3.0`), NewJSONDatum(json.CreateBinary(uint64(3)))},
+		{jsonDatum(`[1, {"a": 2}]`), jsonDatum(`[1.0, {"a": 2.0}]`)},
+		{{}, {}},
+		{MinNotNullDatum(), MinNotNullDatum()},
+		{MaxValueDatum(), MaxValueDatum()},
+	}
+	hashes := make([]uint64, 0, len(groups))
+	for _, group := range groups {
+		h, err := group[0].Hash64(sc, bin)
+		require.NoError(t, err)
+		for _, d := range group[1:] {
+			cmp, err := group[0].Compare(sc, &d, bin)
+			require.NoError(t, err)
+			require.Equal(t, 0, cmp, "%v %v", group[0], d)
+			h1, err := d.Hash64(sc, bin)
+			require.NoError(t, err)
+			require.Equal(t, h, h1, "%v %v", group[0], d)
+		}
+		hashes = append(hashes, h)
+	}
+	// The hashes of unequal datums differ in general.
+	for i := range hashes {
+		for j := i + 1; j < len(hashes); j++ {
+			require.NotEqual(t, hashes[i], hashes[j], "%v %v", groups[i][0], groups[j][0])
+		}
+	}
+
+	// Datums of different classes may compare equal but hash differently.
+	crossClass := [][2]Datum{
+		{NewIntDatum(1), NewStringDatum("1")},
+		{NewMysqlEnumDatum(Enum{Name: "a", Value: 1}), NewIntDatum(1)},
+		{NewMysqlSetDatum(Set{Name: "a", Value: 1}, ""), NewStringDatum("a")},
+	}
+	for _, pair := range crossClass {
+		cmp, err := pair[0].Compare(sc, &pair[1], bin)
+		require.NoError(t, err)
+		require.Equal(t, 0, cmp, "%v %v", pair[0], pair[1])
+		h0, err := pair[0].Hash64(sc, bin)
+		require.NoError(t, err)
+		h1, err := pair[1].Hash64(sc, bin)
+		require.NoError(t, err)
+		require.NotEqual(t, h0, h1, "%v %v", pair[0], pair[1])
+	}
+
+	d := NewDatum(struct{}{})
+	_, err := d.Hash64(sc, bin)
+	require.Error(t, err)
+}
+
+// hashClass returns the class of datums which Hash64 keeps consistent with Compare.
+func hashClass(d *Datum) byte {
+	switch d.k {
+	case KindInt64, KindUint64, KindFloat32, KindFloat64, KindMysqlDecimal:
+		return KindInt64
+	case KindString, KindBytes, KindMysqlEnum, KindBinaryLiteral, KindMysqlBit:
+		return KindString
+	}
+	return d.k
+}
+
+func TestHash64Randomized(t *testing.T) {
+	t.Parallel()
+	// Set the compare_seed environment variable to reproduce a counterexample.
+	seed := int64(1)
+	if s := os.Getenv("compare_seed"); s != "" {
+		var err error
+		seed, err = strconv.ParseInt(s, 10, 64)
+		require.NoError(t, err)
+	}
+	r := rand.New(rand.NewSource(seed))
+	sc := new(stmtctx.StatementContext)
+	bin := collate.GetBinaryCollator()
+
+	checked := 0
+	for i := 0; i < 10000; i++ {
+		a, b := randomCompareDatum(r), randomCompareDatum(r)
+		if hashClass(&a) != hashClass(&b) {
+			continue
+		}
+		cmp, err := a.Compare(sc, &b, bin)
+		require.NoError(t, err, "seed %d: %v %v", seed, a, b)
+		if cmp != 0 {
+			continue
+		}
+		ha, err := a.Hash64(sc, bin)
+		require.NoError(t, err, "seed %d: %v", seed, a)
+		hb, err := b.Hash64(sc, bin)
+		require.NoError(t, err, "seed %d: %v", seed, b)
+		require.Equal(t, ha, hb, "seed %d: %v %v", seed, a, b)
+		checked++
+	}
+	require.Greater(t, checked, 0)
+}
+
+func TestVecCompareDatum(t *testing.T) {
+	t.Parallel()
+	r := rand.New(rand.NewSource(1))
+	sc := new(stmtctx.StatementContext)
+	bin := collate.GetBinaryCollator()
+	gens := []func() Datum{
+		func() Datum { return NewIntDatum(r.Int63n(21) - 10) },
+		func() Datum { return NewUintDatum(uint64(r.Int63n(11))) },
+		func() Datum { return NewUintDatum(math.MaxUint64 - uint64(r.Int63n(2))) },
+		func() Datum { return NewFloat64Datum(float64(r.Int63n(41)-20) / 4) },
+		func() Datum { return NewFloat32Datum(float32(r.Int63n(41)-20) / 4) },
+		func() Datum { return NewDecimalDatum(NewDecFromFloatForTest(float64(r.Int63n(41)-20) / 4)) },
+		func() Datum { return NewStringDatum(strconv.FormatInt(r.Int63n(21)-10, 10)) },
+		func() Datum { return NewBytesDatum([]byte(strconv.FormatInt(r.Int63n(21)-10, 10))) },
+		func() Datum {
+			return NewDurationDatum(Duration{Duration: time.Duration(r.Int63n(21)-10) * time.Second})
+		},
+	}
+	check := func(x, y []Datum) {
+		res := make([]int64, len(x))
+		require.NoError(t, VecCompareDatum(sc, x, y, bin, res))
+		for i := range x {
+			cmp, err := x[i].Compare(sc, &y[i], bin)
+			require.NoError(t, err)
+			require.Equal(t, int64(cmp), res[i], "%v %v", x[i], y[i])
+		}
+	}
+	const n = 64
+	// Every pair of kinds, which are batched by the specialized functions if they support them.
+	for _, gx := range gens {
+		for _, gy := range gens {
+			x, y := make([]Datum, n), make([]Datum, n)
+			for i := 0; i < n; i++ {
+				x[i], y[i] = gx(), gy()
+			}
+			check(x, y)
+		}
+	}
+	// Mixed kinds fall back to Datum.Compare.
+	for k := 0; k < 20; k++ {
+		x, y := make([]Datum, n), make([]Datum, n)
+		for i := 0; i < n; i++ {
+			x[i] = gens[r.Intn(len(gens))]()
+			if x[i].k == KindMysqlDuration {
+				y[i] = gens[len(gens)-1]()
+			} else {
+				y[i] = gens[r.Intn(len(gens)-1)]()
+			}
+		}
+		check(x, y)
+	}
+
+	// NaN is compared the same as Datum.Compare does, batched or not.
+	nans := []func() Datum{
+		func() Datum { return NewFloat64Datum(math.NaN()) },
+		func() Datum { return NewFloat32Datum(float32(math.NaN())) },
+	}
+	for _, gn := range nans {
+		for _, g := range append(nans, gens[:5]...) {
+			x, y := make([]Datum, n), make([]Datum, n)
+			for i := 0; i < n; i++ {
+				x[i], y[i] = gn(), g()
+			}
+			check(x, y)
+			check(y, x)
+		}
+	}
+
+	// Comparing a batch of one kind reuses the pooled buffers.
+	ints := []Datum{NewIntDatum(1), NewIntDatum(2), NewIntDatum(3)}
+	res := make([]int64, len(ints))
+	allocs := testing.AllocsPerRun(100, func() {
+		_ = VecCompareDatum(sc, ints, ints, bin, res)
+	})
+	require.Zero(t, allocs)
+	require.Equal(t, []int64{0, 0, 0}, res)
+
+	// A nil sc is fine.
+	res = make([]int64, 2)
+	require.NoError(t, VecCompareDatum(nil, []Datum{NewIntDatum(1), NewStringDatum("1")}, []Datum{NewDecimalDatum(NewDecFromInt(2)), NewIntDatum(1)}, bin, res))
+	require.Equal(t, []int64{-1, 0}, res)
+	require.NoError(t, VecCompareDatum(sc, nil, nil, bin, nil))
+	require.Error(t, VecCompareDatum(sc, []Datum{NewIntDatum(1)}, nil, bin, res))
+	require.Error(t, VecCompareDatum(sc, []Datum{NewIntDatum(1), NewIntDatum(1), NewIntDatum(1)}, []Datum{NewIntDatum(1), NewIntDatum(1), NewIntDatum(1)}, bin, res))
+}
+
+// BenchmarkVecCompareDatum compares batches of datums by VecCompareDatum and by Datum.Compare one
+// by one. A batch of one kind on each side pays for extracting the values into typed slices, which
+// is paid back by the tight loop of the specialized VecCompare* function, so it's faster than
+// comparing the datums one by one, e.g. for int64 and decimal. A batch of mixed kinds first finds
+// out they are mixed and then compares every pair by Datum.Compare, so it's only slightly slower
+// than the plain loop.
+func BenchmarkVecCompareDatum(b *testing.B) {
+	const n = 1024
+	sc := new(stmtctx.StatementContext)
+	bin := collate.GetBinaryCollator()
+	batches := map[string][2][]Datum{}
+	ints, decs, mixed := [2][]Datum{}, [2][]Datum{}, [2][]Datum{}
+	for j := 0; j < 2; j++ {
+		ints[j], decs[j], mixed[j] = make([]Datum, n), make([]Datum, n), make([]Datum, n)
+		for i := 0; i < n; i++ {
+			ints[j][i] = NewIntDatum(int64(i * (j + 1)))
+			decs[j][i] = NewDecimalDatum(NewDecFromFloatForTest(float64(i*(j+1)) / 8))
+			if i%2 == 0 {
+				mixed[j][i] = ints[j][i]
+			} else {
+				mixed[j][i] = decs[j][i]
+			}
+		}
+	}
+	batches["int64"], batches["decimal"], batches["mixed"] = ints, decs, mixed
+	res := make([]int64, n)
+	for _, name := range []string{"int64", "decimal", "mixed"} {
+		x, y := batches[name][0], batches[name][1]
+		b.Run(name+"/Compare", func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				for j := range x {
+					cmp, err := x[j].Compare(sc, &y[j], bin)
+					if err != nil {
+						b.Fatal(err)
+					}
+					res[j] = int64(cmp)
+				}
+			}
+		})
+		b.Run(name+"/VecCompareDatum", func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				if err := VecCompareDatum(sc, x, y, bin, res); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func TestTotalOrderCompare(t *testing.T) {
+	t.Parallel()
+	jsonDatum := func(s string) Datum {
+		j, err := json.ParseBinaryFromString(s)
+		require.NoError(t, err)
+		return NewJSONDatum(j)
+	}
+	ct := FromDate(2023, 1, 2, 3, 4, 5, 0)
+	// One datum of each kind in the order of the kinds.
+	sorted := []Datum{
+		{},
+		NewIntDatum(1),
+		NewUintDatum(1),
+		NewFloat32Datum(1),
+		NewFloat64Datum(1),
+		NewStringDatum("1"),
+		NewBytesDatum([]byte("1")),
+		NewBinaryLiteralDatum(BinaryLiteral{'1'}),
+		NewDecimalDatum(NewDecFromInt(1)),
+		NewDurationDatum(Duration{Duration: time.Second}),
+		NewMysqlEnumDatum(Enum{Name: "1", Value: 1}),
+		NewMysqlBitDatum(BinaryLiteral{1}),
+		NewMysqlSetDatum(Set{Name: "1", Value: 1}, mysql.DefaultCollationName),
+		NewTimeDatum(NewTime(ct, mysql.TypeDatetime, 0)),
+		MinNotNullDatum(),
+		MaxValueDatum(),
+		jsonDatum(`1`),
+	}
+	for i := range sorted {
+		if i > 0 {
+			require.Less(t, sorted[i-1].Kind(), sorted[i].Kind())
+		}
+	}
+	r := rand.New(rand.NewSource(1))
+	for k := 0; k < 10; k++ {
+		ds := append([]Datum(nil), sorted...)
+		r.Shuffle(len(ds), func(i, j int) { ds[i], ds[j] = ds[j], ds[i] })
+		sort.SliceStable(ds, func(i, j int) bool { return ds[i].TotalOrderCompare(&ds[j]) < 0 })
+		require.Equal(t, sorted, ds)
+	}
+
+	// Values of the same kind are ordered without coercion.
+	sameKind := [][]Datum{
+		{NewIntDatum(math.MinInt64), NewIntDatum(-1), NewIntDatum(0), NewIntDatum(math.MaxInt64)},
+		{NewFloat64Datum(math.NaN()), NewFloat64Datum(math.Inf(-1)), NewFloat64Datum(-1), NewFloat64Datum(0.5), NewFloat64Datum(math.Inf(1))},
+		{NewStringDatum(""), NewStringDatum("A"), NewStringDatum("B"), NewStringDatum("a"), NewStringDatum("a ")},
+		{NewMysqlEnumDatum(Enum{Name: "z", Value: 1}), NewMysqlEnumDatum(Enum{Name: "a", Value: 2})},
+		{NewDurationDatum(Duration{Duration: time.Second, Fsp: 0}), NewDurationDatum(Duration{Duration: time.Second, Fsp: 3})},
+		{NewTimeDatum(NewTime(ct, mysql.TypeTimestamp, 0)), NewTimeDatum(NewTime(ct, mysql.TypeDatetime, 0)), NewTimeDatum(NewTime(ct, mysql.TypeDatetime, 6))},
+		{jsonDatum(`null`), jsonDatum(`1`), jsonDatum(`1.0`), jsonDatum(`2`), jsonDatum(`"a"`)},
+	}
+	for _, ds := range sameKind {
+		for i := range ds {
+			for j := range ds {
+				require.Equal(t, CompareInt64(int64(i), int64(j)), ds[i].TotalOrderCompare(&ds[j]), "%v %v", ds[i], ds[j])
+			}
+		}
+	}
+
+	// Antisymmetric and transitive.
+	var pool []Datum
+	pool = append(pool, sorted...)
+	for _, ds := range sameKind {
+		pool = append(pool, ds...)
+	}
+	for i := 0; i < 100; i++ {
+		pool = append(pool, randomCompareDatum(r))
+	}
+	for i := range pool {
+		for j := range pool {
+			ij := pool[i].TotalOrderCompare(&pool[j])
+			require.Equal(t, ij, -pool[j].TotalOrderCompare(&pool[i]), "%v %v", pool[i], pool[j])
+		}
+	}
+	for n := 0; n < 10000; n++ {
+		a, b, c := &pool[r.Intn(len(pool))], &pool[r.Intn(len(pool))], &pool[r.Intn(len(pool))]
+		ab, bc, ac := a.TotalOrderCompare(b), b.TotalOrderCompare(c), a.TotalOrderCompare(c)
+		if ab <= 0 && bc <= 0 {
+			require.LessOrEqual(t, ac, 0, "%v %v %v", a, b, c)
+		}
+		if ab == 0 && bc == 0 {
+			require.Equal(t, 0, ac, "%v %v %v", a, b, c)
+		}
+	}
+}
@@ -127,6 +127,16 @@ func ConvertIntToInt(val int64, lowerBound int64, upperBound int64, tp byte) (in
 	return val, nil
 }
 
+// ConvertIntToIntChecked converts an int value to the signed integer type tp like ConvertIntToInt
+// with the bounds of tp, but returns 0 instead of the clamped value on overflow, for the callers
+// which fail on overflow rather than clamp with a warning, e.g. INSERT in strict mode.
+func ConvertIntToIntChecked(val int64, tp byte) (int64, error) {
+	if val < IntergerSignedLowerBound(tp) || val > IntergerSignedUpperBound(tp) {
+		return 0, overflow(val, tp)
+	}
+	return val, nil
+}
+
 // ConvertUintToInt converts an uint value to an int value.
 func ConvertUintToInt(val uint64, upperBound int64, tp byte) (int64, error) {
 	if val > uint64(upperBound) {
@@ -158,6 +168,15 @@ func ConvertUintToUint(val uint64, upperBound uint64, tp byte) (uint64, error) {
 	return val, nil
 }
 
+// ConvertUintToUintChecked is the unsigned counterpart of ConvertIntToIntChecked, it returns 0
+// instead of the clamped value if val overflows the unsigned integer type tp.
+func ConvertUintToUintChecked(val uint64, tp byte) (uint64, error) {
+	if val > IntergerUnsignedUpperBound(tp) {
+		return 0, overflow(val, tp)
+	}
+	return val, nil
+}
+
 // ConvertFloatToUint converts a float value to an uint value.
 func ConvertFloatToUint(sc *stmtctx.StatementContext, fval float64, upperBound uint64, tp byte) (uint64, error) {
 	val := RoundFloat(fval)
@@ -182,6 +201,19 @@ func ConvertFloatToUint(sc *stmtctx.StatementContext, fval float64, upperBound u
 	return uint64(val), nil
 }
 
+// ConvertFloatToDecimalWithMode converts a float value to a decimal rounded to frac digits with mode.
+// The float is first taken as its shortest decimal representation, the same as MyDecimal.FromFloat64,
+// and then rounded once, so 0.145 rounds to 0.15 under ModeHalfUp even though the nearest float is
+// slightly below 0.145, and to 0.14 under ModeHalfToEven.
+func ConvertFloatToDecimalWithMode(f float64, mode RoundMode, frac int) (*MyDecimal, error) {
+	dec := new(MyDecimal)
+	if err := dec.FromFloat64(f); err != nil {
+		return nil, errors.Trace(err)
+	}
+	err := dec.Round(dec, frac, mode)
+	return dec, errors.Trace(err)
+}
+
 // convertScientificNotation converts a decimal string with scientific notation to a normal decimal string.
 // 1E6 => 1000000, .12345E+5 => 12345
 func convertScientificNotation(str string) (string, error) {
@@ -552,7 +584,7 @@ func ConvertJSONToInt64(sc *stmtctx.StatementContext, j json.BinaryJSON, unsigne
 // ConvertJSONToInt casts JSON into int by type.
 func ConvertJSONToInt(sc *stmtctx.StatementContext, j json.BinaryJSON, unsigned bool, tp byte) (int64, error) {
 	switch j.TypeCode {
-	case json.TypeCodeObject, json.TypeCodeArray:
+	case json.TypeCodeObject, json.TypeCodeArray, json.TypeCodeOpaque:
 		return 0, sc.HandleTruncate(ErrTruncatedWrongVal.GenWithStackByArgs("INTEGER", j.String()))
 	case json.TypeCodeLiteral:
 		switch j.Value[0] {
@@ -610,7 +642,7 @@ func ConvertJSONToInt(sc *stmtctx.StatementContext, j json.BinaryJSON, unsigned
 // ConvertJSONToFloat casts JSON into float64.
 func ConvertJSONToFloat(sc *stmtctx.StatementContext, j json.BinaryJSON) (float64, error) {
 	switch j.TypeCode {
-	case json.TypeCodeObject, json.TypeCodeArray:
+	case json.TypeCodeObject, json.TypeCodeArray, json.TypeCodeOpaque:
 		return 0, sc.HandleTruncate(ErrTruncatedWrongVal.GenWithStackByArgs("FLOAT", j.String()))
 	case json.TypeCodeLiteral:
 		switch j.Value[0] {
@@ -637,7 +669,7 @@ func ConvertJSONToDecimal(sc *stmtctx.StatementContext, j json.BinaryJSON) (*MyD
 	var err error = nil
 	res := new(MyDecimal)
 	switch j.TypeCode {
-	case json.TypeCodeObject, json.TypeCodeArray:
+	case json.TypeCodeObject, json.TypeCodeArray, json.TypeCodeOpaque:
 		err = ErrTruncatedWrongVal.GenWithStackByArgs("DECIMAL", j.String())
 	case json.TypeCodeLiteral:
 		switch j.Value[0] {
@@ -662,6 +694,46 @@ func ConvertJSONToDecimal(sc *stmtctx.StatementContext, j json.BinaryJSON) (*MyD
 	return res, errors.Trace(err)
 }
 
+// ExtractDatumFromJSON extracts the value at path in j as a Datum of the matching kind, so it can
+// be compared without formatting it as a string first. A JSON true or false becomes 1 or 0, and
+// a JSON null or a missing path becomes NULL, while an object, an array or an opaque value stays
+// as JSON. A path with wildcards may match multiple values, so it's rejected, BinaryJSON.Extract
+// returns all the matched values in an array instead.
+func ExtractDatumFromJSON(j json.BinaryJSON, path string) (Datum, error) {
+	pathExpr, err := json.ParseJSONPathExpr(path)
+	if err != nil {
+		return Datum{}, errors.Trace(err)
+	}
+	if pathExpr.ContainsAnyAsterisk() {
+		return Datum{}, json.ErrInvalidJSONPathWildcard.GenWithStackByArgs()
+	}
+	v, found := j.Extract([]json.PathExpression{pathExpr})
+	if !found {
+		return Datum{}, nil
+	}
+	switch v.TypeCode {
+	case json.TypeCodeLiteral:
+		switch v.Value[0] {
+		case json.LiteralTrue:
+			return NewIntDatum(1), nil
+		case json.LiteralFalse:
+			return NewIntDatum(0), nil
+		default:
+			return Datum{}, nil
+		}
+	case json.TypeCodeInt64:
+		return NewIntDatum(v.GetInt64()), nil
+	case json.TypeCodeUint64:
+		return NewUintDatum(v.GetUint64()), nil
+	case json.TypeCodeFloat64:
+		return NewFloat64Datum(v.GetFloat64()), nil
+	case json.TypeCodeString:
+		return NewStringDatum(string(v.GetString())), nil
+	default:
+		return NewJSONDatum(v.Copy()), nil
+	}
+}
+
 // getValidFloatPrefix gets prefix of string which can be successfully parsed as float.
 func getValidFloatPrefix(sc *stmtctx.StatementContext, s string, isFuncCast bool) (valid string, err error) {
 	if isFuncCast && s == "" {
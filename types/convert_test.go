@@ -27,6 +27,7 @@ import (
 	"github.com/pingcap/tidb/parser/terror"
 	"github.com/pingcap/tidb/sessionctx/stmtctx"
 	"github.com/pingcap/tidb/types/json"
+	"github.com/pingcap/tidb/util/collate"
 	"github.com/stretchr/testify/require"
 )
 
@@ -327,6 +328,169 @@ func TestConvertType(t *testing.T) {
 	require.Error(t, err)
 }
 
+func TestConvertJSONToString(t *testing.T) {
+	t.Parallel()
+	sc := new(stmtctx.StatementContext)
+	ft := NewFieldType(mysql.TypeVarString)
+	ft.Flen = UnspecifiedLength
+	inputs := []string{
+		`{"b": [1, {"y": 2, "x": 1}], "a": null, "c": "str"}`,
+		`{"c":"str","a":null,"b":[1,{"x":1,"y":2}]}`,
+		`  { "a" : null , "c" : "str" , "b" : [ 1 , { "y" : 2 , "x" : 1 } ] }  `,
+	}
+	for _, input := range inputs {
+		bj, err := json.ParseBinaryFromString(input)
+		require.NoError(t, err)
+		d := NewJSONDatum(bj)
+		casted, err := d.ConvertTo(sc, ft)
+		require.NoError(t, err)
+		// Keys are sorted, and the separators are the same as MySQL's.
+		require.Equal(t, `{"a": null, "b": [1, {"x": 1, "y": 2}], "c": "str"}`, casted.GetString(), input)
+	}
+}
+
+func TestToEnumByValue(t *testing.T) {
+	t.Parallel()
+	// The names of the elements coincide with numbers of other indexes.
+	elems := []string{"2", "1", "x"}
+	tests := []struct {
+		d      Datum
+		result string
+	}{
+		{NewStringDatum("2"), "1"},
+		{NewStringDatum(" 1 "), "2"},
+		{NewIntDatum(2), "1"},
+		{NewUintDatum(3), "x"},
+		{NewFloat64Datum(1.9), "2"},
+		{NewDecimalDatum(NewDecFromStringForTest("2.5")), "1"},
+	}
+	for _, tt := range tests {
+		e, err := tt.d.ToEnumByValue(elems)
+		require.NoError(t, err, tt.d)
+		require.Equal(t, tt.result, e.Name, tt.d)
+	}
+
+	errTests := []Datum{
+		NewStringDatum("x"),
+		NewStringDatum("4"),
+		NewStringDatum("0"),
+		NewIntDatum(-1),
+		NewIntDatum(0),
+		NewUintDatum(4),
+		NewFloat64Datum(0.5),
+		NewFloat64Datum(4),
+		NewDecimalDatum(NewDecFromStringForTest("-1")),
+	}
+	for _, d := range errTests {
+		_, err := d.ToEnumByValue(elems)
+		require.Truef(t, terror.ErrorEqual(err, ErrTruncated), "%v: %v", d, err)
+	}
+	d := NewDurationDatum(ZeroDuration)
+	_, err := d.ToEnumByValue(elems)
+	require.Error(t, err)
+}
+
+func TestToEnumWithCase(t *testing.T) {
+	t.Parallel()
+	elems := []string{"a", "B", "Straße"}
+	tests := []struct {
+		d             Datum
+		caseSensitive bool
+		result        string
+	}{
+		{NewStringDatum("a"), true, "a"},
+		{NewStringDatum("B"), true, "B"},
+		{NewStringDatum("Straße"), true, "Straße"},
+		{NewStringDatum("A"), false, "a"},
+		{NewStringDatum("b"), false, "B"},
+		{NewStringDatum("straße"), false, "Straße"},
+		{NewStringDatum("2"), true, "B"},
+		{NewBytesDatum([]byte("a")), true, "a"},
+		{NewIntDatum(3), true, "Straße"},
+		{NewUintDatum(1), false, "a"},
+	}
+	for _, tt := range tests {
+		e, err := tt.d.ToEnumWithCase(elems, tt.caseSensitive)
+		require.NoError(t, err, tt.d)
+		require.Equal(t, tt.result, e.Name, tt.d)
+	}
+
+	// The collation of the column doesn't matter, a case-variant name is rejected if case-sensitive.
+	_, err := ParseEnum(elems, "A", "utf8mb4_general_ci")
+	require.NoError(t, err)
+	for _, d := range []Datum{NewStringDatum("A"), NewStringDatum("b"), NewStringDatum("straße"), NewStringDatum("a "), NewStringDatum("c"), NewStringDatum("4")} {
+		_, err := d.ToEnumWithCase(elems, true)
+		require.Truef(t, terror.ErrorEqual(err, ErrTruncated), "%v: %v", d, err)
+	}
+	for _, d := range []Datum{NewStringDatum("c"), NewStringDatum("0"), NewIntDatum(0)} {
+		_, err := d.ToEnumWithCase(elems, false)
+		require.Truef(t, terror.ErrorEqual(err, ErrTruncated), "%v: %v", d, err)
+	}
+}
+
+func TestConvertFloatToEnum(t *testing.T) {
+	t.Parallel()
+	ft := NewFieldType(mysql.TypeEnum)
+	ft.Elems = []string{"a", "b", "c"}
+
+	sc := new(stmtctx.StatementContext)
+	d := NewFloat64Datum(2.7)
+	v, err := d.ConvertTo(sc, ft)
+	require.NoError(t, err)
+	require.Equal(t, Enum{Name: "b", Value: 2}, v.GetMysqlEnum())
+	require.Len(t, sc.GetWarnings(), 1)
+	require.True(t, ErrTruncatedWrongVal.Equal(sc.GetWarnings()[0].Err))
+
+	sc = new(stmtctx.StatementContext)
+	d = NewFloat64Datum(3)
+	v, err = d.ConvertTo(sc, ft)
+	require.NoError(t, err)
+	require.Equal(t, Enum{Name: "c", Value: 3}, v.GetMysqlEnum())
+	require.Len(t, sc.GetWarnings(), 0)
+
+	for _, f := range []float64{0.5, -1.5, 4, 3.99e10} {
+		d = NewFloat64Datum(f)
+		_, err = d.ConvertTo(sc, ft)
+		require.Truef(t, terror.ErrorEqual(err, ErrTruncated), "%v err %v", f, err)
+	}
+}
+
+func TestConvertIntToSet(t *testing.T) {
+	t.Parallel()
+	ft := NewFieldType(mysql.TypeSet)
+	ft.Elems = []string{"a", "b", "c"}
+	sc := new(stmtctx.StatementContext)
+
+	tbl := []struct {
+		val uint64
+		set Set
+	}{
+		{0, Set{}},
+		{1, Set{Name: "a", Value: 1}},
+		{3, Set{Name: "a,b", Value: 3}},
+		{5, Set{Name: "a,c", Value: 5}},
+		{7, Set{Name: "a,b,c", Value: 7}},
+	}
+	for _, tt := range tbl {
+		d := NewUintDatum(tt.val)
+		v, err := d.ConvertTo(sc, ft)
+		require.NoError(t, err)
+		require.Equal(t, tt.set, v.GetMysqlSet())
+
+		d = NewIntDatum(int64(tt.val))
+		v, err = d.ConvertTo(sc, ft)
+		require.NoError(t, err)
+		require.Equal(t, tt.set, v.GetMysqlSet())
+	}
+
+	// The bits beyond the element count are invalid.
+	for _, val := range []uint64{8, 15, 1 << 63} {
+		d := NewUintDatum(val)
+		_, err := d.ConvertTo(sc, ft)
+		require.Truef(t, terror.ErrorEqual(err, ErrTruncated), "%d err %v", val, err)
+	}
+}
+
 func testToString(t *testing.T, val interface{}, expect string) {
 	b, err := ToString(val)
 	require.NoError(t, err)
@@ -929,6 +1093,60 @@ func TestGetValidFloat(t *testing.T) {
 	}
 }
 
+func TestConvertToMysqlYear(t *testing.T) {
+	t.Parallel()
+	sc := new(stmtctx.StatementContext)
+	ft := NewFieldType(mysql.TypeYear)
+	tests := []struct {
+		in         int64
+		year       int64
+		outOfRange bool
+	}{
+		{1, 2001, false},
+		{69, 2069, false},
+		{70, 1970, false},
+		{99, 1999, false},
+		{100, 1901, true},
+		{1900, 1901, true},
+		{1901, 1901, false},
+		{2155, 2155, false},
+		{2156, 2155, true},
+		{-1, 0, true},
+	}
+	for _, tt := range tests {
+		// Integers, strings and floats are the same.
+		for _, d := range []Datum{NewIntDatum(tt.in), NewStringDatum(strconv.FormatInt(tt.in, 10)), NewFloat64Datum(float64(tt.in))} {
+			ret, err := d.ConvertToMysqlYear(sc, ft)
+			if tt.outOfRange {
+				require.Truef(t, terror.ErrorEqual(err, ErrWarnDataOutOfRange), "%v: err %v", d, err)
+			} else {
+				require.NoError(t, err, d)
+			}
+			require.Equal(t, tt.year, ret.GetInt64(), d)
+		}
+	}
+
+	// A numeric 0 is the special year 0000, while a string of zeros shorter than 4 characters is 2000.
+	for _, tt := range []struct {
+		d    Datum
+		year int64
+	}{
+		{NewIntDatum(0), 0},
+		{NewFloat64Datum(0), 0},
+		{NewDecimalDatum(NewDecFromInt(0)), 0},
+		{NewStringDatum("0"), 2000},
+		{NewStringDatum("00"), 2000},
+		{NewStringDatum(" 0"), 2000},
+		{NewStringDatum("0000"), 0},
+		// A float is rounded before the two-digit mapping.
+		{NewFloat64Datum(69.5), 1970},
+	} {
+		ret, err := tt.d.ConvertToMysqlYear(sc, ft)
+		require.NoError(t, err, tt.d)
+		require.Equal(t, tt.year, ret.GetInt64(), tt.d)
+	}
+}
+
 // TestConvertTime tests time related conversion.
 // time conversion is complicated including Date/Datetime/Time/Timestamp etc,
 // Timestamp may involving timezone.
@@ -988,6 +1206,116 @@ func testConvertTimeTimeZone(t *testing.T, sc *stmtctx.StatementContext) {
 	}
 }
 
+func TestConvertStringWithTimezoneToTime(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		input  string
+		tz     *time.Location
+		expect string
+	}{
+		// An explicit offset is converted to the session time zone.
+		{"2023-01-01 12:00:00+08:00", time.UTC, "2023-01-01 04:00:00"},
+		{"2023-01-01 12:00:00+08:00", time.FixedZone("", 3*3600), "2023-01-01 07:00:00"},
+		{"2023-01-01 12:00:00-05:30", time.UTC, "2023-01-01 17:30:00"},
+		{"2023-01-01 02:00:00+08:00", time.UTC, "2022-12-31 18:00:00"},
+		{"2023-01-01 12:00:00.5+08:00", time.UTC, "2023-01-01 04:00:01"},
+		// A time without an offset is in the session time zone as-is.
+		{"2023-01-01 12:00:00", time.UTC, "2023-01-01 12:00:00"},
+		{"2023-01-01 12:00:00", time.FixedZone("", 3*3600), "2023-01-01 12:00:00"},
+	}
+	for _, tt := range tests {
+		sc := &stmtctx.StatementContext{TimeZone: tt.tz}
+		for _, tp := range []byte{mysql.TypeDatetime, mysql.TypeTimestamp} {
+			d := NewStringDatum(tt.input)
+			nd, err := d.ConvertTo(sc, NewFieldType(tp))
+			require.NoError(t, err, tt.input)
+			require.Equal(t, tp, nd.GetMysqlTime().Type())
+			require.Equal(t, tt.expect, nd.GetMysqlTime().String(), tt.input)
+		}
+	}
+
+	sc := &stmtctx.StatementContext{TimeZone: time.UTC}
+	d := NewStringDatum("2023-01-01 12:00:00+15:00")
+	_, err := d.ConvertTo(sc, NewFieldType(mysql.TypeDatetime))
+	require.Truef(t, terror.ErrorEqual(err, ErrWrongValue), "err %v", err)
+}
+
+func TestConvertBinaryLiteralToTime(t *testing.T) {
+	t.Parallel()
+	sc := &stmtctx.StatementContext{TimeZone: time.UTC}
+	tests := []struct {
+		input  Datum
+		tp     byte
+		expect string
+	}{
+		{NewMysqlBitDatum(NewBinaryLiteralFromUint(20230101, -1)), mysql.TypeDate, "2023-01-01"},
+		{NewMysqlBitDatum(NewBinaryLiteralFromUint(20230101, 8)), mysql.TypeDatetime, "2023-01-01 00:00:00"},
+		{NewBinaryLiteralDatum(NewBinaryLiteralFromUint(20230101, -1)), mysql.TypeDate, "2023-01-01"},
+		{NewMysqlBitDatum(NewBinaryLiteralFromUint(20230101123456, -1)), mysql.TypeDatetime, "2023-01-01 12:34:56"},
+		{NewMysqlBitDatum(NewBinaryLiteralFromUint(20230101123456, -1)), mysql.TypeDate, "2023-01-01"},
+		{NewMysqlBitDatum(NewBinaryLiteralFromUint(20230101123456, -1)), mysql.TypeTimestamp, "2023-01-01 12:34:56"},
+	}
+	for _, tt := range tests {
+		nd, err := tt.input.ConvertTo(sc, NewFieldType(tt.tp))
+		require.NoError(t, err)
+		require.Equal(t, tt.tp, nd.GetMysqlTime().Type())
+		require.Equal(t, tt.expect, nd.GetMysqlTime().String())
+
+		// The same as converting the integer value.
+		u, err := tt.input.GetBinaryLiteral().ToInt(sc)
+		require.NoError(t, err)
+		ud := NewUintDatum(u)
+		expected, err := ud.ConvertTo(sc, NewFieldType(tt.tp))
+		require.NoError(t, err)
+		require.Equal(t, 0, expected.GetMysqlTime().Compare(nd.GetMysqlTime()))
+	}
+
+	// A value that is not a valid numeric datetime is an error, just like the integer.
+	d := NewMysqlBitDatum(NewBinaryLiteralFromUint(20231301, -1))
+	_, err := d.ConvertTo(sc, NewFieldType(mysql.TypeDate))
+	require.Truef(t, terror.ErrorEqual(err, ErrWrongValue), "err %v", err)
+	d = NewMysqlBitDatum(NewBinaryLiteralFromUint(math.MaxUint64, -1))
+	_, err = d.ConvertTo(sc, NewFieldType(mysql.TypeDatetime))
+	require.Truef(t, terror.ErrorEqual(err, ErrWrongValue), "err %v", err)
+}
+
+func TestConvertFloatToDecimalWithMode(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		f          float64
+		frac       int
+		halfUp     string
+		halfToEven string
+		truncate   string
+	}{
+		{2.5, 0, "3", "2", "2"},
+		{3.5, 0, "4", "4", "3"},
+		{-2.5, 0, "-3", "-2", "-2"},
+		{-3.5, 0, "-4", "-4", "-3"},
+		{2.25, 1, "2.3", "2.2", "2.2"},
+		{2.2501, 1, "2.3", "2.3", "2.2"},
+		{0.0000000005, 9, "0.000000001", "0.000000000", "0.000000000"},
+		{25, -1, "30", "20", "20"},
+		{12.34, 4, "12.3400", "12.3400", "12.3400"},
+		// The nearest float to 0.145 is slightly below it, but the float is read as
+		// its shortest representation "0.145" and rounded only once from there.
+		{0.145, 2, "0.15", "0.14", "0.14"},
+		{2.675, 2, "2.68", "2.68", "2.67"},
+		// No intermediate rounding to fewer digits turns this into a tie.
+		{0.12499999999999999, 2, "0.12", "0.12", "0.12"},
+	}
+	for _, tt := range tests {
+		for mode, expect := range map[RoundMode]string{ModeHalfUp: tt.halfUp, ModeHalfToEven: tt.halfToEven, ModeTruncate: tt.truncate} {
+			dec, err := ConvertFloatToDecimalWithMode(tt.f, mode, tt.frac)
+			require.NoError(t, err)
+			require.Equal(t, expect, dec.String(), "%v to %d with mode %d", tt.f, tt.frac, mode)
+		}
+	}
+
+	_, err := ConvertFloatToDecimalWithMode(math.NaN(), ModeHalfUp, 2)
+	require.Error(t, err)
+}
+
 func TestConvertJSONToInt(t *testing.T) {
 	t.Parallel()
 	var tests = []struct {
@@ -1064,6 +1392,54 @@ func TestConvertJSONToDecimal(t *testing.T) {
 	}
 }
 
+func TestExtractDatumFromJSON(t *testing.T) {
+	t.Parallel()
+	j, err := json.ParseBinaryFromString(`{"i": -3, "u": 18446744073709551615, "f": 4.5, "s": "abc", "t": true, "n": null, "a": [1, "2", false], "o": {"k": 1}}`)
+	require.NoError(t, err)
+	sc := new(stmtctx.StatementContext)
+	tests := []struct {
+		path   string
+		expect Datum
+	}{
+		{"$.i", NewIntDatum(-3)},
+		{"$.u", NewUintDatum(math.MaxUint64)},
+		{"$.f", NewFloat64Datum(4.5)},
+		{"$.s", NewStringDatum("abc")},
+		{"$.t", NewIntDatum(1)},
+		{"$.a[2]", NewIntDatum(0)},
+		{"$.a[1]", NewStringDatum("2")},
+		{"$.o.k", NewIntDatum(1)},
+		{"$.n", Datum{}},
+		{"$.missing", Datum{}},
+		{"$.a[10]", Datum{}},
+		{"$.a", NewJSONDatum(json.CreateBinary([]interface{}{int64(1), "2", false}))},
+		{"$.o", NewJSONDatum(json.CreateBinary(map[string]interface{}{"k": int64(1)}))},
+	}
+	for _, tt := range tests {
+		d, err := ExtractDatumFromJSON(j, tt.path)
+		require.NoError(t, err, tt.path)
+		require.Equal(t, tt.expect.Kind(), d.Kind(), tt.path)
+		cmp, err := d.Compare(sc, &tt.expect, collate.GetBinaryCollator())
+		require.NoError(t, err)
+		require.Equal(t, 0, cmp, tt.path)
+	}
+
+	// The extracted value compares as a number without going through a string.
+	d, err := ExtractDatumFromJSON(j, "$.f")
+	require.NoError(t, err)
+	dec := NewDecimalDatum(NewDecFromStringForTest("4.50"))
+	cmp, err := d.Compare(sc, &dec, collate.GetBinaryCollator())
+	require.NoError(t, err)
+	require.Equal(t, 0, cmp)
+
+	for _, path := range []string{"$.a[*]", "$.*", "$**.k"} {
+		_, err = ExtractDatumFromJSON(j, path)
+		require.True(t, json.ErrInvalidJSONPathWildcard.Equal(err), path)
+	}
+	_, err = ExtractDatumFromJSON(j, "a")
+	require.True(t, json.ErrInvalidJSONPath.Equal(err))
+}
+
 func TestNumberToDuration(t *testing.T) {
 	t.Parallel()
 	var testCases = []struct {
@@ -1136,6 +1512,41 @@ func TestStrToDuration(t *testing.T) {
 	}
 }
 
+func TestConvertDurationToDecimal(t *testing.T) {
+	t.Parallel()
+	sc := new(stmtctx.StatementContext)
+	tests := []struct {
+		dur    time.Duration
+		fsp    int8
+		result string
+	}{
+		{time.Hour + 2*time.Minute + 3*time.Second, 0, "10203"},
+		{time.Hour + 2*time.Minute + 3*time.Second + 500*time.Millisecond, 1, "10203.5"},
+		{time.Hour + 2*time.Minute + 3*time.Second + 500*time.Millisecond, 3, "10203.500"},
+		{time.Hour + 2*time.Minute + 3*time.Second + 123*time.Microsecond, 6, "10203.000123"},
+		{-(time.Hour + 2*time.Minute + 3*time.Second + 250*time.Millisecond), 2, "-10203.25"},
+		{838*time.Hour + 59*time.Minute + 59*time.Second, 6, "8385959.000000"},
+		{0, 2, "0.00"},
+	}
+	for _, tt := range tests {
+		d := NewDurationDatum(Duration{Duration: tt.dur, Fsp: tt.fsp})
+		v, err := d.ConvertTo(sc, NewFieldType(mysql.TypeNewDecimal))
+		require.NoError(t, err)
+		dec := v.GetMysqlDecimal()
+		// The fsp of the duration is the scale of the decimal.
+		require.Equal(t, tt.result, dec.String())
+		require.Equal(t, tt.fsp, dec.GetDigitsFrac())
+	}
+
+	// An explicit scale rounds it.
+	d := NewDurationDatum(Duration{Duration: time.Hour + 2*time.Minute + 3*time.Second + 500*time.Millisecond, Fsp: 3})
+	tp := NewFieldType(mysql.TypeNewDecimal)
+	tp.Flen, tp.Decimal = 10, 1
+	v, err := d.ConvertTo(sc, tp)
+	require.NoError(t, err)
+	require.Equal(t, "10203.5", v.GetMysqlDecimal().String())
+}
+
 func TestConvertScientificNotation(t *testing.T) {
 	t.Parallel()
 	cases := []struct {
@@ -1204,3 +1615,50 @@ func TestConvertDecimalStrToUint(t *testing.T) {
 		}
 	}
 }
+
+func TestConvertIntToIntChecked(t *testing.T) {
+	t.Parallel()
+	tps := []byte{mysql.TypeTiny, mysql.TypeShort, mysql.TypeInt24, mysql.TypeLong, mysql.TypeLonglong}
+	for _, tp := range tps {
+		lower, upper := IntergerSignedLowerBound(tp), IntergerSignedUpperBound(tp)
+		for _, v := range []int64{lower, upper, 0, -1, 1} {
+			val, err := ConvertIntToIntChecked(v, tp)
+			require.NoError(t, err)
+			require.Equal(t, v, val)
+		}
+		if tp == mysql.TypeLonglong {
+			continue
+		}
+		for _, v := range []int64{lower - 1, upper + 1, math.MinInt64, math.MaxInt64} {
+			val, err := ConvertIntToIntChecked(v, tp)
+			require.Truef(t, terror.ErrorEqual(err, ErrOverflow), "tp %d, val %d, err %v", tp, v, err)
+			require.Equal(t, int64(0), val)
+
+			// ConvertIntToInt clamps instead.
+			val, err = ConvertIntToInt(v, lower, upper, tp)
+			require.Truef(t, terror.ErrorEqual(err, ErrOverflow), "tp %d, val %d, err %v", tp, v, err)
+			require.NotEqual(t, int64(0), val)
+		}
+	}
+}
+
+func TestConvertUintToUintChecked(t *testing.T) {
+	t.Parallel()
+	tps := []byte{mysql.TypeTiny, mysql.TypeShort, mysql.TypeInt24, mysql.TypeLong, mysql.TypeLonglong}
+	for _, tp := range tps {
+		upper := IntergerUnsignedUpperBound(tp)
+		for _, v := range []uint64{0, 1, upper} {
+			val, err := ConvertUintToUintChecked(v, tp)
+			require.NoError(t, err)
+			require.Equal(t, v, val)
+		}
+		if tp == mysql.TypeLonglong {
+			continue
+		}
+		for _, v := range []uint64{upper + 1, math.MaxUint64} {
+			val, err := ConvertUintToUintChecked(v, tp)
+			require.Truef(t, terror.ErrorEqual(err, ErrOverflow), "tp %d, val %d, err %v", tp, v, err)
+			require.Equal(t, uint64(0), val)
+		}
+	}
+}
@@ -15,16 +15,21 @@
 package types
 
 import (
+	"bytes"
+	"encoding/binary"
 	"fmt"
 	"math"
+	"math/big"
 	"sort"
 	"strconv"
 	"strings"
 	"time"
+	"unicode"
 	"unicode/utf8"
 	"unsafe"
 
 	"github.com/pingcap/errors"
+	"github.com/pingcap/tidb/config"
 	"github.com/pingcap/tidb/parser/charset"
 	"github.com/pingcap/tidb/parser/mysql"
 	"github.com/pingcap/tidb/parser/terror"
@@ -33,6 +38,8 @@ import (
 	"github.com/pingcap/tidb/types/json"
 	"github.com/pingcap/tidb/util/collate"
 	"github.com/pingcap/tidb/util/hack"
+	"github.com/pingcap/tidb/util/israce"
+	"github.com/twmb/murmur3"
 )
 
 // Kind constants.
@@ -93,6 +100,21 @@ func (d *Datum) Copy(dst *Datum) {
 	}
 }
 
+// CloneDeep returns a copy of the Datum which shares no backing array with d, so mutating the
+// bytes of either one never affects the other. Numeric kinds are copied without any allocation.
+func (d *Datum) CloneDeep() Datum {
+	switch d.k {
+	case KindNull, KindInt64, KindUint64, KindFloat32, KindFloat64, KindMysqlDuration, KindMinNotNull, KindMaxValue:
+		ret := *d
+		// b is unused by these kinds, drop it instead of sharing a stale slice.
+		ret.b = nil
+		return ret
+	}
+	var ret Datum
+	d.Copy(&ret)
+	return ret
+}
+
 // Kind gets the kind of the datum.
 func (d *Datum) Kind() byte {
 	return d.k
@@ -133,6 +155,13 @@ func (d *Datum) IsNull() bool {
 	return d.k == KindNull
 }
 
+// IsZeroTemporal checks if datum holds a zero DATE/DATETIME/TIMESTAMP value, e.g. '0000-00-00'.
+// Unlike NULL, a zero temporal value is a concrete value: it sorts above NULL but below any
+// non-zero time in Compare.
+func (d *Datum) IsZeroTemporal() bool {
+	return d.k == KindMysqlTime && d.GetMysqlTime().IsZero()
+}
+
 // GetInt64 gets int64 value.
 func (d *Datum) GetInt64() int64 {
 	return d.i
@@ -234,6 +263,16 @@ func (d *Datum) SetNull() {
 	d.x = nil
 }
 
+// IsMinNotNull checks if datum is the MinNotNull sentinel, which is less than any value except NULL.
+func (d *Datum) IsMinNotNull() bool {
+	return d.k == KindMinNotNull
+}
+
+// IsMaxValue checks if datum is the MaxValue sentinel, which is greater than any value.
+func (d *Datum) IsMaxValue() bool {
+	return d.k == KindMaxValue
+}
+
 // SetMinNotNull sets datum to minNotNull value.
 func (d *Datum) SetMinNotNull() {
 	d.k = KindMinNotNull
@@ -261,6 +300,15 @@ func (d *Datum) GetBinaryLiteral() BinaryLiteral {
 	return d.b
 }
 
+// MustGetBinaryLiteral gets Bit value like GetBinaryLiteral, but panics if the datum is neither a
+// KindBinaryLiteral nor a KindMysqlBit datum, rather than returning the raw bytes of any kind.
+func (d *Datum) MustGetBinaryLiteral() BinaryLiteral {
+	if d.k != KindBinaryLiteral && d.k != KindMysqlBit {
+		panic(fmt.Sprintf("MustGetBinaryLiteral called on a datum of kind %s", KindStr(d.k)))
+	}
+	return d.b
+}
+
 // GetMysqlBit gets MysqlBit value
 func (d *Datum) GetMysqlBit() BinaryLiteral {
 	return d.GetBinaryLiteral()
@@ -554,55 +602,544 @@ func (d *Datum) SetValue(val interface{}, tp *types.FieldType) {
 // Notes: don't rely on datum.collation to get the collator, it's tend to buggy.
 // TODO: use this function to replace CompareDatum. After we remove all of usage of CompareDatum, we can rename this function back to CompareDatum.
 func (d *Datum) Compare(sc *stmtctx.StatementContext, ad *Datum, comparer collate.Collator) (int, error) {
-	if d.k == KindMysqlJSON && ad.k != KindMysqlJSON {
-		cmp, err := ad.Compare(sc, d, comparer)
-		return cmp * -1, errors.Trace(err)
+	if int(d.k) >= len(compareFuncs) || int(ad.k) >= len(compareFuncs) {
+		return 0, nil
 	}
-	switch ad.k {
+	return compareFuncs[d.k][ad.k](sc, d, ad, comparer)
+}
+
+// CompareGroupBy compares datum to another datum for GROUP BY, where NULLs group together: two
+// NULLs are equal and a NULL is less than any value. Unlike CompareNullable, which follows the
+// SQL three-valued logic of WHERE, the result is never unknown.
+func (d *Datum) CompareGroupBy(sc *stmtctx.StatementContext, ad *Datum, comparer collate.Collator) (int, error) {
+	switch {
+	case d.IsNull() && ad.IsNull():
+		return 0, nil
+	case d.IsNull():
+		return -1, nil
+	case ad.IsNull():
+		return 1, nil
+	}
+	return d.Compare(sc, ad, comparer)
+}
+
+// compareFunc compares d to ad, it is picked by the kinds of d and ad.
+type compareFunc func(sc *stmtctx.StatementContext, d, ad *Datum, comparer collate.Collator) (int, error)
+
+// compareFuncs is the dispatch table of Compare indexed by the kinds of both datums, so Compare
+// doesn't go through a type switch for every call.
+var compareFuncs [KindMysqlJSON + 1][KindMysqlJSON + 1]compareFunc
+
+func init() {
+	for dk := range compareFuncs {
+		for ak := range compareFuncs[dk] {
+			compareFuncs[dk][ak] = newCompareFunc(byte(dk), byte(ak))
+		}
+	}
+}
+
+// newCompareFunc returns the compareFunc for a datum of kind dk compared to a datum of kind ak.
+func newCompareFunc(dk, ak byte) compareFunc {
+	// Fast path for the most common comparison between integers of the same kind.
+	if dk == ak {
+		switch dk {
+		case KindInt64:
+			return func(_ *stmtctx.StatementContext, d, ad *Datum, _ collate.Collator) (int, error) {
+				return CompareInt64(d.GetInt64(), ad.GetInt64()), nil
+			}
+		case KindUint64:
+			return func(_ *stmtctx.StatementContext, d, ad *Datum, _ collate.Collator) (int, error) {
+				return CompareUint64(d.GetUint64(), ad.GetUint64()), nil
+			}
+		}
+	}
+	// The result only depends on the kinds when either one is a sentinel.
+	if cmp, ok := compareSentinel(&Datum{k: dk}, &Datum{k: ak}); ok {
+		return func(*stmtctx.StatementContext, *Datum, *Datum, collate.Collator) (int, error) {
+			return cmp, nil
+		}
+	}
+	if dk == KindMysqlJSON && ak != KindMysqlJSON {
+		return func(sc *stmtctx.StatementContext, d, ad *Datum, comparer collate.Collator) (int, error) {
+			cmp, err := ad.Compare(sc, d, comparer)
+			return cmp * -1, errors.Trace(err)
+		}
+	}
+	switch ak {
 	case KindNull:
-		if d.k == KindNull {
-			return 0, nil
+		cmp := 1
+		if dk == KindNull {
+			cmp = 0
 		}
-		return 1, nil
-	case KindMinNotNull:
-		if d.k == KindNull {
-			return -1, nil
-		} else if d.k == KindMinNotNull {
-			return 0, nil
+		return func(*stmtctx.StatementContext, *Datum, *Datum, collate.Collator) (int, error) {
+			return cmp, nil
 		}
-		return 1, nil
-	case KindMaxValue:
-		if d.k == KindMaxValue {
+	case KindInt64:
+		return func(sc *stmtctx.StatementContext, d, ad *Datum, _ collate.Collator) (int, error) {
+			return d.compareInt64(sc, ad.GetInt64())
+		}
+	case KindUint64:
+		return func(sc *stmtctx.StatementContext, d, ad *Datum, _ collate.Collator) (int, error) {
+			return d.compareUint64(sc, ad.GetUint64())
+		}
+	case KindFloat32, KindFloat64:
+		return func(sc *stmtctx.StatementContext, d, ad *Datum, _ collate.Collator) (int, error) {
+			return d.compareFloat64(sc, ad.GetFloat64())
+		}
+	case KindString, KindBytes:
+		return func(sc *stmtctx.StatementContext, d, ad *Datum, comparer collate.Collator) (int, error) {
+			return d.compareStringNew(sc, ad.GetString(), comparer)
+		}
+	case KindMysqlDecimal:
+		return func(sc *stmtctx.StatementContext, d, ad *Datum, _ collate.Collator) (int, error) {
+			return d.compareMysqlDecimal(sc, ad.GetMysqlDecimal())
+		}
+	case KindMysqlDuration:
+		return func(sc *stmtctx.StatementContext, d, ad *Datum, _ collate.Collator) (int, error) {
+			return d.compareMysqlDuration(sc, ad.GetMysqlDuration())
+		}
+	case KindMysqlEnum:
+		return func(sc *stmtctx.StatementContext, d, ad *Datum, comparer collate.Collator) (int, error) {
+			return d.compareMysqlEnumNew(sc, ad.GetMysqlEnum(), comparer)
+		}
+	case KindBinaryLiteral, KindMysqlBit:
+		return func(sc *stmtctx.StatementContext, d, ad *Datum, comparer collate.Collator) (int, error) {
+			return d.compareBinaryLiteralNew(sc, ad.GetBinaryLiteral4Cmp(), comparer)
+		}
+	case KindMysqlSet:
+		return func(sc *stmtctx.StatementContext, d, ad *Datum, comparer collate.Collator) (int, error) {
+			return d.compareMysqlSetNew(sc, ad.GetMysqlSet(), comparer)
+		}
+	case KindMysqlJSON:
+		return func(sc *stmtctx.StatementContext, d, ad *Datum, _ collate.Collator) (int, error) {
+			return d.compareMysqlJSON(sc, ad.GetMysqlJSON())
+		}
+	case KindMysqlTime:
+		return func(sc *stmtctx.StatementContext, d, ad *Datum, _ collate.Collator) (int, error) {
+			return d.compareMysqlTime(sc, ad.GetMysqlTime())
+		}
+	default:
+		return func(*stmtctx.StatementContext, *Datum, *Datum, collate.Collator) (int, error) {
 			return 0, nil
 		}
+	}
+}
+
+// CompareChecked is like Compare, but returns an error if a non-binary collator is supplied
+// while either side is not a string, in which case Compare
+// silently ignores the collator. It is meant for tests and assertions, not for hot paths.
+func (d *Datum) CompareChecked(sc *stmtctx.StatementContext, ad *Datum, comparer collate.Collator) (int, error) {
+	if comparer != nil && !collate.IsBinCollator(comparer) && (!d.isCollatable() || !ad.isCollatable()) {
+		return 0, errors.Errorf("collator is not applicable to comparison between %s and %s",
+			KindStr(d.k), KindStr(ad.k))
+	}
+	return d.Compare(sc, ad, comparer)
+}
+
+// CompareStable is like Compare, but breaks a tie between datums of different kinds by their
+// Kind values, so that the result is deterministic. e.g, int64(1) sorts before "1" because
+// KindInt64 < KindString.
+func (d *Datum) CompareStable(sc *stmtctx.StatementContext, ad *Datum, comparer collate.Collator) (int, error) {
+	cmp, err := d.Compare(sc, ad, comparer)
+	if err != nil || cmp != 0 {
+		return cmp, err
+	}
+	switch {
+	case d.k < ad.k:
 		return -1, nil
+	case d.k > ad.k:
+		return 1, nil
+	}
+	return 0, nil
+}
+
+// IsComparableWith reports whether Compare gives a meaningful result for the kinds of d and
+// other, and whether it needs to convert one side to the type of the other first, e.g. int64
+// and float64 are compared as floats and a string is parsed when compared with a Time. A
+// conversion may fail or be truncated depending on the values, so Compare can still return an
+// error or a warning when needsConversion is true. Integers, floats and strings of different
+// kinds are compared without conversion, and so is anything with NULL or a sentinel.
+func (d *Datum) IsComparableWith(other *Datum) (comparable bool, needsConversion bool) {
+	dc, oc := compareClassOf(d.k), compareClassOf(other.k)
+	switch {
+	case dc == compareClassNone || oc == compareClassNone:
+		return false, false
+	case dc == compareClassSentinel || oc == compareClassSentinel:
+		return true, false
+	}
+	return true, dc != oc
+}
+
+// Compare classes group the kinds that Compare compares without converting either side.
+const (
+	compareClassNone byte = iota
+	compareClassSentinel
+	compareClassInt
+	compareClassFloat
+	compareClassDecimal
+	compareClassString
+	compareClassBinaryLiteral
+	compareClassDuration
+	compareClassTime
+	compareClassEnum
+	compareClassSet
+	compareClassJSON
+)
+
+func compareClassOf(k byte) byte {
+	switch k {
+	case KindNull, KindMinNotNull, KindMaxValue:
+		return compareClassSentinel
+	case KindInt64, KindUint64:
+		return compareClassInt
+	case KindFloat32, KindFloat64:
+		return compareClassFloat
+	case KindMysqlDecimal:
+		return compareClassDecimal
+	case KindString, KindBytes:
+		return compareClassString
+	case KindBinaryLiteral, KindMysqlBit:
+		return compareClassBinaryLiteral
+	case KindMysqlDuration:
+		return compareClassDuration
+	case KindMysqlTime:
+		return compareClassTime
+	case KindMysqlEnum:
+		return compareClassEnum
+	case KindMysqlSet:
+		return compareClassSet
+	case KindMysqlJSON:
+		return compareClassJSON
+	}
+	// KindInterface and KindRaw are never compared by value.
+	return compareClassNone
+}
+
+// TotalOrderCompare returns an integer comparing d to other by a total order over all the kinds,
+// e.g. for sorting datums deterministically in dumps and tests. Datums are ordered by their kinds
+// first, so int64(1) is always before "1", then by their values without any coercion: strings
+// byte by byte regardless of the collation, NaN before any other float, enums and sets by their
+// values, and temporal values, which may tie, by their types and fsp afterwards.
+func (d *Datum) TotalOrderCompare(other *Datum) int {
+	if d.k != other.k {
+		return CompareUint64(uint64(d.k), uint64(other.k))
+	}
+	switch d.k {
 	case KindInt64:
-		return d.compareInt64(sc, ad.GetInt64())
+		return CompareInt64(d.GetInt64(), other.GetInt64())
 	case KindUint64:
-		return d.compareUint64(sc, ad.GetUint64())
+		return CompareUint64(d.GetUint64(), other.GetUint64())
 	case KindFloat32, KindFloat64:
-		return d.compareFloat64(sc, ad.GetFloat64())
-	case KindString:
-		return d.compareStringNew(sc, ad.GetString(), comparer)
-	case KindBytes:
-		return d.compareStringNew(sc, ad.GetString(), comparer)
+		x, y := d.GetFloat64(), other.GetFloat64()
+		switch xNaN, yNaN := math.IsNaN(x), math.IsNaN(y); {
+		case xNaN && yNaN:
+			return 0
+		case xNaN:
+			return -1
+		case yNaN:
+			return 1
+		}
+		return CompareFloat64(x, y)
+	case KindString, KindBytes, KindBinaryLiteral, KindMysqlBit:
+		return bytes.Compare(d.b, other.b)
 	case KindMysqlDecimal:
-		return d.compareMysqlDecimal(sc, ad.GetMysqlDecimal())
+		return d.GetMysqlDecimal().Compare(other.GetMysqlDecimal())
 	case KindMysqlDuration:
-		return d.compareMysqlDuration(sc, ad.GetMysqlDuration())
+		x, y := d.GetMysqlDuration(), other.GetMysqlDuration()
+		if cmp := x.Compare(y); cmp != 0 {
+			return cmp
+		}
+		return CompareInt64(int64(x.Fsp), int64(y.Fsp))
 	case KindMysqlEnum:
-		return d.compareMysqlEnumNew(sc, ad.GetMysqlEnum(), comparer)
-	case KindBinaryLiteral, KindMysqlBit:
-		return d.compareBinaryLiteralNew(sc, ad.GetBinaryLiteral4Cmp(), comparer)
+		x, y := d.GetMysqlEnum(), other.GetMysqlEnum()
+		if cmp := CompareUint64(x.Value, y.Value); cmp != 0 {
+			return cmp
+		}
+		return strings.Compare(x.Name, y.Name)
 	case KindMysqlSet:
-		return d.compareMysqlSetNew(sc, ad.GetMysqlSet(), comparer)
+		x, y := d.GetMysqlSet(), other.GetMysqlSet()
+		if cmp := CompareUint64(x.Value, y.Value); cmp != 0 {
+			return cmp
+		}
+		return strings.Compare(x.Name, y.Name)
+	case KindMysqlTime:
+		x, y := d.GetMysqlTime(), other.GetMysqlTime()
+		if cmp := x.Compare(y); cmp != 0 {
+			return cmp
+		}
+		if cmp := CompareUint64(uint64(x.Type()), uint64(y.Type())); cmp != 0 {
+			return cmp
+		}
+		return CompareInt64(int64(x.Fsp()), int64(y.Fsp()))
 	case KindMysqlJSON:
-		return d.compareMysqlJSON(sc, ad.GetMysqlJSON())
+		x, y := d.GetMysqlJSON(), other.GetMysqlJSON()
+		if cmp := json.CompareBinary(x, y); cmp != 0 {
+			return cmp
+		}
+		if cmp := CompareUint64(uint64(x.TypeCode), uint64(y.TypeCode)); cmp != 0 {
+			return cmp
+		}
+		return bytes.Compare(x.Value, y.Value)
+	}
+	// NULL, MinNotNull and MaxValue are equal to themselves, and interfaces can't be ordered.
+	return 0
+}
+
+// CompareNoPad is like Compare, but treats trailing spaces of strings as significant even if
+// comparer is a PAD SPACE collator, so that "a" < "a " like a NO PAD collation does.
+func (d *Datum) CompareNoPad(sc *stmtctx.StatementContext, ad *Datum, comparer collate.Collator) (int, error) {
+	cmp, err := d.Compare(sc, ad, comparer)
+	if err != nil || cmp != 0 {
+		return cmp, err
+	}
+	if (d.k != KindString && d.k != KindBytes) || (ad.k != KindString && ad.k != KindBytes) {
+		return cmp, nil
+	}
+	// A PAD SPACE collator only ignores trailing spaces, so the string with more of them is
+	// the greater one under NO PAD.
+	s1, s2 := d.GetString(), ad.GetString()
+	spaces1 := len(s1) - len(strings.TrimRight(s1, " "))
+	spaces2 := len(s2) - len(strings.TrimRight(s2, " "))
+	return CompareInt64(int64(spaces1), int64(spaces2)), nil
+}
+
+// CompareCrossCharset is like Compare, but d is a string of charset lhsCharset and other is a
+// string of charset rhsCharset, which are converted to a common charset before being compared.
+// The common charset is the one whose repertoire contains the other, e.g. utf8mb4 for latin1 and
+// utf8mb4, so the conversion never fails. If neither contains the other, e.g. latin1 and gbk,
+// other is converted to lhsCharset like a constant to the charset of the column it's compared to,
+// and an error is returned if it has a character which can't be represented in lhsCharset.
+// Strings are held in utf8mb4 whatever their charsets, so the converted strings are compared under
+// collator as they are, or byte by byte if either charset is binary.
+func (d *Datum) CompareCrossCharset(sc *stmtctx.StatementContext, other *Datum, lhsCharset, rhsCharset string, collator collate.Collator) (int, error) {
+	if (d.k != KindString && d.k != KindBytes) || (other.k != KindString && other.k != KindBytes) {
+		return d.Compare(sc, other, collator)
+	}
+	if lhsCharset == charset.CharsetBin || rhsCharset == charset.CharsetBin {
+		return d.Compare(sc, other, collate.GetBinaryCollator())
+	}
+	if !charsetContains(lhsCharset, rhsCharset) && !charsetContains(rhsCharset, lhsCharset) {
+		if _, err := charset.NewEncoding(lhsCharset).EncodeString(other.GetString()); err != nil {
+			return 0, errors.Trace(err)
+		}
+	}
+	return d.Compare(sc, other, collator)
+}
+
+// charsetContains returns whether every character of charset b can be represented in charset a.
+func charsetContains(a, b string) bool {
+	switch {
+	case a == b, a == charset.CharsetUTF8MB4, b == charset.CharsetASCII:
+		return true
+	case a == charset.CharsetUTF8:
+		// utf8 only lacks the supplementary characters, which are out of the other charsets too.
+		return b != charset.CharsetUTF8MB4
+	}
+	return false
+}
+
+// The leading bytes of the keys hashed by Hash64, they tell apart the classes of datums which
+// Hash64 keeps consistent with Compare.
+const (
+	hashFlagNull byte = iota
+	hashFlagMinNotNull
+	hashFlagMaxValue
+	hashFlagNumber
+	hashFlagString
+	hashFlagDuration
+	hashFlagTime
+	hashFlagJSON
+	hashFlagSet
+)
+
+// Hash64 returns a hash of d for hash join and hash aggregation. It's consistent with Compare
+// only within a class of kinds: two datums of the same class which are equal by Compare under
+// collator hash equal. The classes are the numeric kinds (int, uint, float and decimal), the
+// string kinds (string, bytes, enum and binary literal), sets, durations, times and JSON.
+// Datums of different classes may compare equal and still hash differently, e.g. int64(1) and
+// "1", an enum and the int of its value, or a set and the string of its name, so the keys of a
+// hash table must be converted to one type first.
+// Numbers are hashed by their float64 value, so that int64(1), uint64(1), float64(1) and
+// decimal 1.00 hash equal, strings by the collation key, so that "a" and "A" hash equal under a
+// case-insensitive collator, and sets by their value, like Compare orders them.
+func (d *Datum) Hash64(sc *stmtctx.StatementContext, collator collate.Collator) (uint64, error) {
+	var buf []byte
+	switch d.k {
+	case KindNull:
+		buf = []byte{hashFlagNull}
+	case KindMinNotNull:
+		buf = []byte{hashFlagMinNotNull}
+	case KindMaxValue:
+		buf = []byte{hashFlagMaxValue}
+	case KindInt64:
+		buf = appendHashFloat64(buf, float64(d.GetInt64()))
+	case KindUint64:
+		buf = appendHashFloat64(buf, float64(d.GetUint64()))
+	case KindFloat32, KindFloat64:
+		buf = appendHashFloat64(buf, d.GetFloat64())
+	case KindMysqlDecimal:
+		f, err := d.GetMysqlDecimal().ToFloat64()
+		if err != nil {
+			return 0, errors.Trace(err)
+		}
+		buf = appendHashFloat64(buf, f)
+	case KindString, KindBytes, KindMysqlEnum:
+		buf = append([]byte{hashFlagString}, collator.Key(d.GetString())...)
+	case KindMysqlSet:
+		buf = appendHashUint64([]byte{hashFlagSet}, d.GetMysqlSet().Value)
+	case KindBinaryLiteral, KindMysqlBit:
+		// Leading zero bytes are ignored by Compare like 0x0061 = 0x61.
+		buf = append([]byte{hashFlagString}, collator.Key(d.GetBinaryLiteral4Cmp().ToString())...)
+	case KindMysqlDuration:
+		buf = appendHashUint64([]byte{hashFlagDuration}, uint64(d.GetMysqlDuration().Duration))
 	case KindMysqlTime:
-		return d.compareMysqlTime(sc, ad.GetMysqlTime())
+		// CoreTime doesn't contain the type and fsp, which are ignored by Compare.
+		buf = appendHashUint64([]byte{hashFlagTime}, uint64(d.GetMysqlTime().CoreTime()))
+	case KindMysqlJSON:
+		buf = d.GetMysqlJSON().HashValue([]byte{hashFlagJSON})
 	default:
-		return 0, nil
+		return 0, errors.Errorf("cannot hash datum of %s", KindStr(d.k))
+	}
+	return murmur3.Sum64(buf), nil
+}
+
+func appendHashFloat64(buf []byte, f float64) []byte {
+	// -0 compares equal to 0.
+	if f == 0 {
+		f = 0
+	}
+	return appendHashUint64(append(buf, hashFlagNumber), math.Float64bits(f))
+}
+
+func appendHashUint64(buf []byte, u uint64) []byte {
+	var b [8]byte
+	binary.LittleEndian.PutUint64(b[:], u)
+	return append(buf, b[:]...)
+}
+
+// CommonPrefixLen returns the number of leading characters of d and other which are equal under
+// comparer, e.g. it is 2 for "abc" and "ABd" under a case-insensitive collator. Both datums must
+// be strings, characters are counted rather than bytes.
+func (d *Datum) CommonPrefixLen(other *Datum, comparer collate.Collator) (int, error) {
+	if (d.k != KindString && d.k != KindBytes) || (other.k != KindString && other.k != KindBytes) {
+		return 0, errors.Errorf("cannot get the common prefix of %s and %s", KindStr(d.k), KindStr(other.k))
+	}
+	s1, s2 := d.GetString(), other.GetString()
+	n := 0
+	for len(s1) > 0 && len(s2) > 0 {
+		_, size1 := utf8.DecodeRuneInString(s1)
+		_, size2 := utf8.DecodeRuneInString(s2)
+		if comparer.Compare(s1[:size1], s2[:size2]) != 0 {
+			break
+		}
+		s1, s2 = s1[size1:], s2[size2:]
+		n++
+	}
+	return n, nil
+}
+
+// CompareWithCollationInfo is like Compare, but takes the collation name and also returns the
+// collation effectively applied. It is charset.CollationBin if Compare falls back to a numeric or
+// temporal comparison, e.g. between a BIT and an ENUM, or if the new collation framework is disabled.
+func (d *Datum) CompareWithCollationInfo(sc *stmtctx.StatementContext, ad *Datum, collation string) (int, string, error) {
+	comparer := &recordingCollator{Collator: collate.GetCollator(collation)}
+	cmp, err := d.Compare(sc, ad, comparer)
+	used := charset.CollationBin
+	if comparer.used && collate.NewCollationEnabled() {
+		used = collation
+	}
+	return cmp, used, err
+}
+
+// recordingCollator records whether Compare has consulted the collator.
+type recordingCollator struct {
+	collate.Collator
+	used bool
+}
+
+// Compare implements the collate.Collator interface.
+func (c *recordingCollator) Compare(a, b string) int {
+	c.used = true
+	return c.Collator.Compare(a, b)
+}
+
+// isStringLike returns whether the datum is compared as a string against another string-like datum.
+func (d *Datum) isStringLike() bool {
+	switch d.k {
+	case KindString, KindBytes, KindMysqlEnum, KindMysqlSet, KindBinaryLiteral, KindMysqlBit:
+		return true
+	}
+	return false
+}
+
+// isCollatable returns whether a collator may take effect when comparing the datum.
+// NULL and sentinel datums are collatable as they are compared without looking at the collator.
+func (d *Datum) isCollatable() bool {
+	switch d.k {
+	case KindNull, KindMinNotNull, KindMaxValue:
+		return true
+	}
+	return d.isStringLike()
+}
+
+// Adjacent returns whether other is the immediate successor of d in the domain of ft, so that
+// the ranges ending at d and starting at other can be merged. Only integer types have a discrete
+// successor; other types such as floats, decimals and strings always return false.
+func (d *Datum) Adjacent(sc *stmtctx.StatementContext, other *Datum, comparer collate.Collator, ft *FieldType) (bool, error) {
+	if !IsTypeInteger(ft.Tp) || (other.k != KindInt64 && other.k != KindUint64) {
+		return false, nil
+	}
+	var succ Datum
+	switch d.k {
+	case KindInt64:
+		if v := d.GetInt64(); v == math.MaxInt64 {
+			succ.SetUint64(uint64(v) + 1)
+		} else {
+			succ.SetInt64(v + 1)
+		}
+	case KindUint64:
+		v := d.GetUint64()
+		if v == math.MaxUint64 {
+			return false, nil
+		}
+		succ.SetUint64(v + 1)
+	default:
+		return false, nil
+	}
+	cmp, err := succ.Compare(sc, other, comparer)
+	if err != nil {
+		return false, errors.Trace(err)
 	}
+	return cmp == 0, nil
+}
+
+// compareSentinel compares a and b if either of them is a MaxValue or MinNotNull sentinel.
+// MaxValue is greater than everything except another MaxValue, and MinNotNull is less than
+// everything except NULL and another MinNotNull. ok is false if neither is a sentinel.
+func compareSentinel(a, b *Datum) (cmp int, ok bool) {
+	switch {
+	case a.k == KindMaxValue:
+		if b.k == KindMaxValue {
+			return 0, true
+		}
+		return 1, true
+	case b.k == KindMaxValue:
+		return -1, true
+	case a.k == KindMinNotNull:
+		switch b.k {
+		case KindNull:
+			return 1, true
+		case KindMinNotNull:
+			return 0, true
+		}
+		return -1, true
+	case b.k == KindMinNotNull:
+		if a.k == KindNull {
+			return -1, true
+		}
+		return 1, true
+	}
+	return 0, false
 }
 
 // CompareDatum compares datum to another datum.
@@ -660,6 +1197,25 @@ func (d *Datum) CompareDatum(sc *stmtctx.StatementContext, ad *Datum) (int, erro
 	}
 }
 
+// CompareInt64 compares a KindInt64 datum with i without going through the
+// kind switch of Compare. The caller must check the kind first; a mismatch
+// panics in race-enabled builds and gives an undefined result otherwise.
+func (d *Datum) CompareInt64(i int64) int {
+	if israce.RaceEnabled && d.k != KindInt64 {
+		panic(fmt.Sprintf("CompareInt64 called on a datum of kind %s", KindStr(d.k)))
+	}
+	return CompareInt64(d.i, i)
+}
+
+// CompareUint64 compares a KindUint64 datum with u, the unsigned counterpart
+// of CompareInt64.
+func (d *Datum) CompareUint64(u uint64) int {
+	if israce.RaceEnabled && d.k != KindUint64 {
+		panic(fmt.Sprintf("CompareUint64 called on a datum of kind %s", KindStr(d.k)))
+	}
+	return CompareUint64(d.GetUint64(), u)
+}
+
 func (d *Datum) compareInt64(sc *stmtctx.StatementContext, i int64) (int, error) {
 	switch d.k {
 	case KindMaxValue:
@@ -738,6 +1294,9 @@ func (d *Datum) compareStringNew(sc *stmtctx.StatementContext, s string, compare
 	case KindMaxValue:
 		return 1, nil
 	case KindString, KindBytes:
+		if sharesBacking(d.b, s) {
+			return 0, nil
+		}
 		return comparer.Compare(d.GetString(), s), nil
 	case KindMysqlDecimal:
 		dec := new(MyDecimal)
@@ -764,6 +1323,13 @@ func (d *Datum) compareStringNew(sc *stmtctx.StatementContext, s string, compare
 	}
 }
 
+// sharesBacking reports whether b and s are the same bytes in memory, e.g. both datums hold the
+// same interned string. Such strings are equal under any collation, so comparing them can skip
+// the collator.
+func sharesBacking(b []byte, s string) bool {
+	return len(b) == len(s) && len(b) > 0 && &b[0] == &hack.Slice(s)[0]
+}
+
 func (d *Datum) compareString(sc *stmtctx.StatementContext, s string, retCollation string) (int, error) {
 	switch d.k {
 	case KindNull, KindMinNotNull:
@@ -878,7 +1444,9 @@ func (d *Datum) compareMysqlSetNew(sc *stmtctx.StatementContext, set Set, compar
 		return -1, nil
 	case KindMaxValue:
 		return 1, nil
-	case KindString, KindBytes, KindMysqlEnum, KindMysqlSet:
+	case KindMysqlSet:
+		return CompareUint64(d.GetMysqlSet().Value, set.Value), nil
+	case KindString, KindBytes, KindMysqlEnum:
 		return comparer.Compare(d.GetString(), set.String()), nil
 	default:
 		return d.compareFloat64(sc, set.ToNumber())
@@ -924,7 +1492,9 @@ func (d *Datum) compareMysqlSet(sc *stmtctx.StatementContext, set Set) (int, err
 		return -1, nil
 	case KindMaxValue:
 		return 1, nil
-	case KindString, KindBytes, KindMysqlEnum, KindMysqlSet:
+	case KindMysqlSet:
+		return CompareUint64(d.GetMysqlSet().Value, set.Value), nil
+	case KindString, KindBytes, KindMysqlEnum:
 		return CompareString(d.GetString(), set.String(), d.collation), nil
 	default:
 		return d.compareFloat64(sc, set.ToNumber())
@@ -932,6 +1502,11 @@ func (d *Datum) compareMysqlSet(sc *stmtctx.StatementContext, set Set) (int, err
 }
 
 func (d *Datum) compareMysqlJSON(sc *stmtctx.StatementContext, target json.BinaryJSON) (int, error) {
+	// A JSON null is a value, while SQL NULL is less than any value. Don't let ToMysqlJSON turn
+	// SQL NULL into a JSON null, which would make them equal.
+	if d.k == KindNull {
+		return -1, nil
+	}
 	origin, err := d.ToMysqlJSON()
 	if err != nil {
 		return 0, errors.Trace(err)
@@ -1283,6 +1858,12 @@ func (d *Datum) convertToMysqlTimestamp(sc *stmtctx.StatementContext, target *Fi
 		t, err = ParseTime(sc, d.GetString(), mysql.TypeTimestamp, fsp)
 	case KindInt64:
 		t, err = ParseTimeFromNum(sc, d.GetInt64(), mysql.TypeTimestamp, fsp)
+	case KindBinaryLiteral, KindMysqlBit:
+		var u uint64
+		u, err = d.GetBinaryLiteral().ToInt(sc)
+		if err == nil {
+			t, err = parseTimeFromUint64(sc, u, mysql.TypeTimestamp, fsp)
+		}
 	case KindMysqlDecimal:
 		t, err = ParseTimeFromFloatString(sc, d.GetMysqlDecimal().String(), mysql.TypeTimestamp, fsp)
 	case KindMysqlJSON:
@@ -1305,6 +1886,15 @@ func (d *Datum) convertToMysqlTimestamp(sc *stmtctx.StatementContext, target *Fi
 	return ret, nil
 }
 
+// parseTimeFromUint64 is ParseTimeFromNum for an unsigned number, which is
+// always a wrong value once it overflows int64.
+func parseTimeFromUint64(sc *stmtctx.StatementContext, u uint64, tp byte, fsp int8) (Time, error) {
+	if u > math.MaxInt64 {
+		return ZeroDate, ErrWrongValue.GenWithStackByArgs(TimeStr, strconv.FormatUint(u, 10))
+	}
+	return ParseTimeFromNum(sc, int64(u), tp, fsp)
+}
+
 func (d *Datum) convertToMysqlTime(sc *stmtctx.StatementContext, target *FieldType) (Datum, error) {
 	tp := target.Tp
 	fsp := DefaultFsp
@@ -1332,18 +1922,19 @@ func (d *Datum) convertToMysqlTime(sc *stmtctx.StatementContext, target *FieldTy
 		}
 		t, err = t.RoundFrac(sc, fsp)
 	case KindMysqlDecimal:
-		t, err = ParseTimeFromFloatString(sc, d.GetMysqlDecimal().String(), tp, fsp)
+		t, err = ParseTimeFromDecimal(sc, d.GetMysqlDecimal(), tp, fsp)
 	case KindString, KindBytes:
 		t, err = ParseTime(sc, d.GetString(), tp, fsp)
 	case KindInt64:
 		t, err = ParseTimeFromNum(sc, d.GetInt64(), tp, fsp)
 	case KindUint64:
-		intOverflow64 := d.GetInt64() < 0
-		if intOverflow64 {
-			uNum := strconv.FormatUint(d.GetUint64(), 10)
-			t, err = ZeroDate, ErrWrongValue.GenWithStackByArgs(TimeStr, uNum)
-		} else {
-			t, err = ParseTimeFromNum(sc, d.GetInt64(), tp, fsp)
+		t, err = parseTimeFromUint64(sc, d.GetUint64(), tp, fsp)
+	case KindBinaryLiteral, KindMysqlBit:
+		// A BIT value in a temporal context is interpreted by its integer value.
+		var u uint64
+		u, err = d.GetBinaryLiteral().ToInt(sc)
+		if err == nil {
+			t, err = parseTimeFromUint64(sc, u, tp, fsp)
 		}
 	case KindMysqlJSON:
 		j := d.GetMysqlJSON()
@@ -1368,6 +1959,24 @@ func (d *Datum) convertToMysqlTime(sc *stmtctx.StatementContext, target *FieldTy
 	return ret, nil
 }
 
+// ConvertToTimeDSTAware converts d to a wall clock time in loc and returns the TIMESTAMP of it in
+// UTC, with the fsp of MaxFsp. Unlike converting to TIMESTAMP with the time zone of sc, a time in
+// a DST gap or overlap of loc is resolved by policy instead of failing.
+func (d *Datum) ConvertToTimeDSTAware(sc *stmtctx.StatementContext, loc *time.Location, policy DSTPolicy) (Time, error) {
+	target := NewFieldType(mysql.TypeDatetime)
+	target.Decimal = int(MaxFsp)
+	ret, err := d.convertToMysqlTime(sc, target)
+	if err != nil {
+		return ZeroTimestamp, errors.Trace(err)
+	}
+	t := ret.GetMysqlTime()
+	if err = t.ConvertTimeZoneWithDSTPolicy(loc, time.UTC, policy); err != nil {
+		return ZeroTimestamp, errors.Trace(err)
+	}
+	t.SetType(mysql.TypeTimestamp)
+	return t, nil
+}
+
 func (d *Datum) convertToMysqlDuration(sc *stmtctx.StatementContext, target *FieldType) (Datum, error) {
 	tp := target.Tp
 	fsp := DefaultFsp
@@ -1394,7 +2003,6 @@ func (d *Datum) convertToMysqlDuration(sc *stmtctx.StatementContext, target *Fie
 			return ret, errors.Trace(err)
 		}
 	case KindInt64, KindUint64, KindFloat32, KindFloat64, KindMysqlDecimal:
-		// TODO: We need a ParseDurationFromNum to avoid the cost of converting a num to string.
 		timeStr, err := d.ToString()
 		if err != nil {
 			return ret, errors.Trace(err)
@@ -1412,7 +2020,12 @@ func (d *Datum) convertToMysqlDuration(sc *stmtctx.StatementContext, target *Fie
 		if timeNum < -MaxDuration {
 			return ret, ErrWrongValue.GenWithStackByArgs(TimeStr, timeStr)
 		}
-		t, err := ParseDuration(sc, timeStr, fsp)
+		var t Duration
+		if d.k == KindInt64 || d.k == KindUint64 {
+			t, err = ParseDurationFromNum(sc, timeNum, fsp)
+		} else {
+			t, err = ParseDuration(sc, timeStr, fsp)
+		}
 		ret.SetMysqlDuration(t)
 		if err != nil {
 			return ret, errors.Trace(err)
@@ -1645,6 +2258,19 @@ func (d *Datum) convertToMysqlEnum(sc *stmtctx.StatementContext, target *FieldTy
 		}
 	case KindMysqlSet:
 		e, err = ParseEnum(target.Elems, d.GetMysqlSet().Name, target.Collate)
+	case KindFloat32, KindFloat64:
+		// MySQL truncates rather than rounds a float to the enum index, e.g. 2.7 is the 2nd element.
+		f := d.GetFloat64()
+		index := math.Trunc(f)
+		if index < 1 || index > float64(len(target.Elems)) {
+			errMsg := fmt.Sprintf("convert to MySQL enum failed: number %v overflow enum boundary [1, %d]", f, len(target.Elems))
+			err = errors.Wrap(ErrTruncated, errMsg)
+			break
+		}
+		if index != f {
+			sc.AppendWarning(ErrTruncatedWrongVal.GenWithStackByArgs("ENUM", strconv.FormatFloat(f, 'g', -1, 64)))
+		}
+		e, err = ParseEnumValue(target.Elems, uint64(index))
 	default:
 		var uintDatum Datum
 		uintDatum, err = d.convertToUint(sc, target)
@@ -1658,6 +2284,70 @@ func (d *Datum) convertToMysqlEnum(sc *stmtctx.StatementContext, target *FieldTy
 	return ret, err
 }
 
+// ToEnumByValue converts a numeric datum to the enum of elems by its 1-based index, a name is
+// never looked up, e.g. "2" is the 2nd element even if an element is named "2". A string must
+// hold an unsigned integer, and a fractional number is truncated to the index.
+func (d *Datum) ToEnumByValue(elems []string) (Enum, error) {
+	switch d.k {
+	case KindInt64:
+		if d.GetInt64() < 0 {
+			errMsg := fmt.Sprintf("convert to MySQL enum failed: number %d overflow enum boundary [1, %d]", d.GetInt64(), len(elems))
+			return Enum{}, errors.Wrap(ErrTruncated, errMsg)
+		}
+		return ParseEnumValue(elems, uint64(d.GetInt64()))
+	case KindUint64:
+		return ParseEnumValue(elems, d.GetUint64())
+	case KindFloat32, KindFloat64:
+		f := math.Trunc(d.GetFloat64())
+		if f < 1 || f > float64(len(elems)) {
+			errMsg := fmt.Sprintf("convert to MySQL enum failed: number %v overflow enum boundary [1, %d]", d.GetFloat64(), len(elems))
+			return Enum{}, errors.Wrap(ErrTruncated, errMsg)
+		}
+		return ParseEnumValue(elems, uint64(f))
+	case KindMysqlDecimal:
+		dec := new(MyDecimal)
+		if err := d.GetMysqlDecimal().Round(dec, 0, ModeTruncate); err != nil {
+			return Enum{}, errors.Trace(err)
+		}
+		i, err := dec.ToInt()
+		if err != nil || i < 0 {
+			errMsg := fmt.Sprintf("convert to MySQL enum failed: number %s overflow enum boundary [1, %d]", d.GetMysqlDecimal(), len(elems))
+			return Enum{}, errors.Wrap(ErrTruncated, errMsg)
+		}
+		return ParseEnumValue(elems, uint64(i))
+	case KindString, KindBytes:
+		num, err := strconv.ParseUint(strings.TrimSpace(d.GetString()), 10, 64)
+		if err != nil {
+			errMsg := fmt.Sprintf("convert to MySQL enum failed: %s is not an index of enum %v", d.GetString(), elems)
+			return Enum{}, errors.Wrap(ErrTruncated, errMsg)
+		}
+		return ParseEnumValue(elems, num)
+	default:
+		return Enum{}, errors.Errorf("cannot convert %s to an enum by value", KindStr(d.k))
+	}
+}
+
+// ToEnumWithCase converts d to the enum of elems like ParseEnum, but a name is matched exactly if
+// caseSensitive, or case-insensitively otherwise, regardless of the collation of the column.
+// A number, or a string which is not a name, is converted by its index like ToEnumByValue.
+func (d *Datum) ToEnumWithCase(elems []string, caseSensitive bool) (Enum, error) {
+	if d.k != KindString && d.k != KindBytes {
+		return d.ToEnumByValue(elems)
+	}
+	name := d.GetString()
+	for i, n := range elems {
+		if n == name || (!caseSensitive && strings.EqualFold(n, name)) {
+			return Enum{Name: n, Value: uint64(i) + 1}, nil
+		}
+	}
+	// name doesn't exist, maybe an integer?
+	if num, err := strconv.ParseUint(name, 0, 64); err == nil {
+		return ParseEnumValue(elems, num)
+	}
+	errMsg := fmt.Sprintf("convert to MySQL enum failed: item %s is not in enum %v", name, elems)
+	return Enum{}, errors.Wrap(ErrTruncated, errMsg)
+}
+
 func (d *Datum) convertToMysqlSet(sc *stmtctx.StatementContext, target *FieldType) (Datum, error) {
 	var (
 		ret Datum
@@ -1720,6 +2410,45 @@ func (d *Datum) convertToMysqlJSON(sc *stmtctx.StatementContext, target *FieldTy
 	return ret, errors.Trace(err)
 }
 
+// ConvertToJSONOpaque converts a binary value to an opaque JSON keeping the MySQL type of the
+// value as its tag, like MySQL does for a BLOB or BINARY value put into JSON, e.g.
+// "base64:type15:yv4=". A string of the binary string type source, a binary literal or a BIT
+// value is binary; any other datum is converted like ConvertTo does.
+// ConvertTo never builds an opaque JSON, so the JSON values it stores can still be read by
+// every reader of the JSON binary format.
+func (d *Datum) ConvertToJSONOpaque(sc *stmtctx.StatementContext, source *FieldType) (ret Datum, err error) {
+	switch d.k {
+	case KindString, KindBytes:
+		if !IsBinaryStr(source) {
+			break
+		}
+		ret.SetMysqlJSON(json.CreateBinary(json.Opaque{TypeCode: source.Tp, Buf: d.GetBytes()}))
+		return ret, nil
+	case KindBinaryLiteral:
+		ret.SetMysqlJSON(json.CreateBinary(json.Opaque{TypeCode: mysql.TypeVarchar, Buf: d.GetBytes()}))
+		return ret, nil
+	case KindMysqlBit:
+		ret.SetMysqlJSON(json.CreateBinary(json.Opaque{TypeCode: mysql.TypeBit, Buf: d.GetBytes()}))
+		return ret, nil
+	}
+	return d.convertToMysqlJSON(sc, NewFieldType(mysql.TypeJSON))
+}
+
+// ConvertToJSONBoolean converts a datum holding a boolean, which is an int64 like any other
+// integer, to the JSON true or false literal rather than the JSON number ConvertTo would give.
+// It is for values whose field type has the IsBooleanFlag, like CAST(bool AS JSON) does.
+func (d *Datum) ConvertToJSONBoolean(sc *stmtctx.StatementContext) (ret Datum, err error) {
+	if d.IsNull() {
+		return ret, nil
+	}
+	b, err := d.ToBool(sc)
+	if err != nil {
+		return ret, errors.Trace(err)
+	}
+	ret.SetMysqlJSON(json.CreateBinary(b != 0))
+	return ret, nil
+}
+
 // ToBool converts to a bool.
 // We will use 1 for true, and 0 for false.
 func (d *Datum) ToBool(sc *stmtctx.StatementContext) (int64, error) {
@@ -1818,6 +2547,67 @@ func (d *Datum) ToDecimal(sc *stmtctx.StatementContext) (*MyDecimal, error) {
 	}
 }
 
+// ToDecimalStrict converts to a decimal like ToDecimal, but a string which is not a valid number,
+// e.g. "hello", reports the "Truncated incorrect DECIMAL value" error rather than ErrTruncated.
+// The error is handled by sc like ToDecimal does, so when truncation is ignored or treated as a
+// warning, the valid prefix of the string is returned, e.g. 0 for "hello".
+func (d *Datum) ToDecimalStrict(sc *stmtctx.StatementContext) (*MyDecimal, error) {
+	if d.k != KindString && d.k != KindBytes {
+		return d.ToDecimal(sc)
+	}
+	dec := new(MyDecimal)
+	err := dec.FromString(d.GetBytes())
+	if terror.ErrorEqual(err, ErrTruncated) || terror.ErrorEqual(err, ErrBadNumber) {
+		err = ErrTruncatedWrongVal.GenWithStackByArgs("DECIMAL", d.GetString())
+	}
+	if err = sc.HandleTruncate(err); err != nil {
+		return nil, err
+	}
+	return dec, nil
+}
+
+// ToUnsignedDecimal converts to a decimal fitting an UNSIGNED DECIMAL(prec, scale) column.
+// A negative value is clamped to zero and clamped is set; the overflow is reported as a
+// warning or returned as an error depending on sc.OverflowAsWarning.
+func (d *Datum) ToUnsignedDecimal(sc *stmtctx.StatementContext, prec, scale int) (dec *MyDecimal, clamped bool, err error) {
+	dec, err = d.ToDecimal(sc)
+	if err != nil {
+		return nil, false, errors.Trace(err)
+	}
+	tp := NewFieldType(mysql.TypeNewDecimal)
+	tp.Flen, tp.Decimal = prec, scale
+	if dec.IsNegative() && !dec.IsZero() {
+		clamped = true
+		dec = new(MyDecimal)
+		overflowErr := ErrOverflow.GenWithStackByArgs("DECIMAL", fmt.Sprintf("(%d, %d)", prec, scale))
+		if err = sc.HandleOverflow(overflowErr, overflowErr); err != nil {
+			return nil, true, err
+		}
+	}
+	dec, err = ProduceDecWithSpecifiedTp(dec, tp, sc)
+	return dec, clamped, err
+}
+
+// ToUintStrict converts to a uint64 for an unsigned integer column. A negative source always
+// returns ErrOverflow, while a fractional one is truncated with a warning or returns the error
+// depending on sc.TruncateAsWarning, e.g. 1.9 is 1 when it is lenient.
+func (d *Datum) ToUintStrict(sc *stmtctx.StatementContext) (uint64, error) {
+	dec, err := d.ToDecimal(sc)
+	if err != nil {
+		return 0, errors.Trace(err)
+	}
+	u, err := dec.ToUint()
+	switch err {
+	case nil:
+		return u, nil
+	case ErrTruncated:
+		err = sc.HandleTruncate(ErrTruncatedWrongVal.GenWithStackByArgs("BIGINT UNSIGNED", dec.String()))
+		return u, errors.Trace(err)
+	default:
+		return 0, ErrOverflow.GenWithStackByArgs("BIGINT UNSIGNED", dec.String())
+	}
+}
+
 // ToInt64 converts to a int64.
 func (d *Datum) ToInt64(sc *stmtctx.StatementContext) (int64, error) {
 	switch d.Kind() {
@@ -1944,6 +2734,30 @@ func (d *Datum) ToFloat64(sc *stmtctx.StatementContext) (float64, error) {
 	}
 }
 
+// ToFloat64Checked converts to a float64 like ToFloat64, and reports whether precision is lost
+// because the integer or decimal value can't be exactly represented in float64.
+func (d *Datum) ToFloat64Checked(sc *stmtctx.StatementContext) (f float64, lostPrecision bool, err error) {
+	f, err = d.ToFloat64(sc)
+	if err != nil {
+		return f, false, errors.Trace(err)
+	}
+	exact := new(big.Rat)
+	switch d.k {
+	case KindInt64:
+		exact.SetInt64(d.GetInt64())
+	case KindUint64:
+		exact.SetUint64(d.GetUint64())
+	case KindMysqlDecimal:
+		if _, ok := exact.SetString(d.GetMysqlDecimal().String()); !ok {
+			return f, true, nil
+		}
+	default:
+		return f, false, nil
+	}
+	converted := new(big.Rat).SetFloat64(f)
+	return f, converted == nil || converted.Cmp(exact) != 0, nil
+}
+
 // ToString gets the string representation of the datum.
 func (d *Datum) ToString() (string, error) {
 	switch d.Kind() {
@@ -1980,6 +2794,108 @@ func (d *Datum) ToString() (string, error) {
 	}
 }
 
+// ToStringStrict gets the string representation of the datum and validates it against charset `chs`.
+// Invalid characters produce an "Incorrect string value" error which goes through sc.HandleTruncate,
+// so it is only returned in strict mode. Otherwise the invalid characters are replaced with '?'.
+func (d *Datum) ToStringStrict(sc *stmtctx.StatementContext, chs string) (string, error) {
+	s, err := d.ToString()
+	if err != nil {
+		return "", errors.Trace(err)
+	}
+	v := NewStringValidator(chs)
+	if v == nil {
+		return s, nil
+	}
+	newStr, invalidPos := v.Truncate(s, charset.TruncateStrategyReplace)
+	if invalidPos < 0 {
+		return s, nil
+	}
+	err = ErrTruncatedWrongValueForField.GenWithStack("Incorrect string value '%s'", FormatInvalidStrValue(s, invalidPos))
+	if err = sc.HandleTruncate(err); err != nil {
+		return "", err
+	}
+	return newStr, nil
+}
+
+// ToStringTruncateInvalid gets the string representation of the datum, cut before the first
+// character which is invalid in charset `chs`, and whether it was cut. The cut is always at a
+// character boundary, e.g. "a中\xffb" gives "a中" for utf8mb4.
+func (d *Datum) ToStringTruncateInvalid(chs string) (string, bool, error) {
+	s, err := d.ToString()
+	if err != nil {
+		return "", false, errors.Trace(err)
+	}
+	v := NewStringValidator(chs)
+	if v == nil {
+		return s, false, nil
+	}
+	truncated, invalidPos := v.Truncate(s, charset.TruncateStrategyTrim)
+	return truncated, invalidPos >= 0, nil
+}
+
+// ConvertDatumToString gets the string representation of d encoded in charset `chs`, e.g. for a
+// client whose results charset isn't utf8mb4. A character which can't be represented in `chs`
+// produces an error which goes through sc.HandleTruncate like ToStringStrict, and is replaced with
+// '?' if it isn't returned. A binary string is returned untouched, and NULL returns
+// ErrWrongValueForType as it has no string representation. Like charset.NewEncoding, an unknown
+// charset is taken as utf8mb4.
+func ConvertDatumToString(sc *stmtctx.StatementContext, d Datum, chs string) (string, error) {
+	if d.IsNull() {
+		return "", ErrWrongValueForType.GenWithStackByArgs("string", "NULL", "ConvertDatumToString")
+	}
+	switch d.Kind() {
+	case KindBytes, KindBinaryLiteral, KindMysqlBit:
+		return d.ToString()
+	}
+	if d.Collation() == charset.CollationBin {
+		return d.ToString()
+	}
+	s, err := d.ToStringStrict(sc, chs)
+	if err != nil {
+		return "", err
+	}
+	encoded, err := charset.NewEncoding(chs).EncodeString(s)
+	if err = sc.HandleTruncate(err); err != nil {
+		return "", err
+	}
+	return encoded, nil
+}
+
+// NewStringValidator returns the validator for the values of charset `chs`, or nil if every byte
+// sequence is valid in it.
+func NewStringValidator(chs string) charset.StringValidator {
+	switch chs {
+	case charset.CharsetASCII:
+		return charset.StringValidatorASCII{}
+	case charset.CharsetUTF8:
+		needCheckMB4 := config.GetGlobalConfig().CheckMb4ValueInUTF8
+		return charset.StringValidatorUTF8{IsUTF8MB4: false, CheckMB4ValueInUTF8: needCheckMB4}
+	case charset.CharsetUTF8MB4:
+		return charset.StringValidatorUTF8{IsUTF8MB4: true}
+	case charset.CharsetLatin1, charset.CharsetBinary:
+		return nil
+	default:
+		return charset.StringValidatorOther{Charset: chs}
+	}
+}
+
+// FormatInvalidStrValue formats at most 6 bytes of s starting at pos, in the same way as MySQL
+// prints the value of an "Incorrect string value" error.
+func FormatInvalidStrValue(s string, pos int) string {
+	var sb strings.Builder
+	for i := pos; i < len(s) && i < pos+6; i++ {
+		if s[i] > unicode.MaxASCII {
+			fmt.Fprintf(&sb, "\\x%X", s[i])
+		} else {
+			sb.WriteByte(s[i])
+		}
+	}
+	if len(s) > pos+6 {
+		sb.WriteString("...")
+	}
+	return sb.String()
+}
+
 // ToBytes gets the bytes representation of the datum.
 func (d *Datum) ToBytes() ([]byte, error) {
 	switch d.k {
@@ -2027,6 +2943,21 @@ func (d *Datum) ToMysqlJSON() (j json.BinaryJSON, err error) {
 	return
 }
 
+// ParseStringAsJSON parses a string datum as a JSON document like inserting it into a JSON
+// column does, rather than wrapping it as a JSON string like ToMysqlJSON. e.g. '"abc"' is the
+// JSON string abc, while the unquoted 'abc' returns the invalid JSON text error.
+func (d *Datum) ParseStringAsJSON(sc *stmtctx.StatementContext) (json.BinaryJSON, error) {
+	switch d.Kind() {
+	case KindString, KindBytes:
+		j, err := json.ParseBinaryFromString(d.GetString())
+		return j, errors.Trace(err)
+	case KindMysqlJSON:
+		return d.GetMysqlJSON(), nil
+	default:
+		return json.BinaryJSON{}, errors.Errorf("cannot parse datum of %s as JSON", KindStr(d.Kind()))
+	}
+}
+
 func invalidConv(d *Datum, tp byte) (Datum, error) {
 	return Datum{}, errors.Errorf("cannot convert datum from %s to type %s.", KindStr(d.Kind()), TypeStr(tp))
 }
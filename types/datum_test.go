@@ -21,7 +21,9 @@ import (
 	"strconv"
 	"testing"
 	"time"
+	"unicode/utf8"
 
+	"github.com/pingcap/tidb/parser/charset"
 	"github.com/pingcap/tidb/parser/mysql"
 	"github.com/pingcap/tidb/sessionctx/stmtctx"
 	"github.com/pingcap/tidb/types/json"
@@ -218,6 +220,141 @@ func mustParseTimeIntoDatum(s string, tp byte, fsp int8) (d Datum) {
 	return
 }
 
+func TestToFloat64Checked(t *testing.T) {
+	t.Parallel()
+	sc := new(stmtctx.StatementContext)
+	tests := []struct {
+		d    Datum
+		f    float64
+		lost bool
+	}{
+		{NewDecimalDatum(NewDecFromStringForTest("1.25")), 1.25, false},
+		{NewDecimalDatum(NewDecFromStringForTest("-4096")), -4096, false},
+		{NewDecimalDatum(NewDecFromStringForTest("12345678901234567891")), 12345678901234567891, true},
+		{NewDecimalDatum(NewDecFromStringForTest("0.1")), 0.1, true},
+		{NewIntDatum(1 << 53), 1 << 53, false},
+		{NewIntDatum(1<<53 + 1), 1 << 53, true},
+		{NewUintDatum(math.MaxUint64), math.MaxUint64, true},
+		{NewFloat64Datum(0.1), 0.1, false},
+	}
+	for _, tt := range tests {
+		f, lost, err := tt.d.ToFloat64Checked(sc)
+		require.NoError(t, err)
+		require.Equal(t, tt.f, f)
+		require.Equal(t, tt.lost, lost, "%v", tt.d)
+	}
+}
+
+func TestToUnsignedDecimal(t *testing.T) {
+	t.Parallel()
+	d := NewDecimalDatum(NewDecFromStringForTest("-1.5"))
+
+	sc := new(stmtctx.StatementContext)
+	sc.OverflowAsWarning = true
+	dec, clamped, err := d.ToUnsignedDecimal(sc, 10, 2)
+	require.NoError(t, err)
+	require.True(t, clamped)
+	require.Equal(t, "0.00", dec.String())
+	require.Len(t, sc.GetWarnings(), 1)
+	require.True(t, ErrOverflow.Equal(sc.GetWarnings()[0].Err))
+
+	sc = new(stmtctx.StatementContext)
+	_, clamped, err = d.ToUnsignedDecimal(sc, 10, 2)
+	require.True(t, ErrOverflow.Equal(err))
+	require.True(t, clamped)
+	require.Len(t, sc.GetWarnings(), 0)
+
+	d = NewDecimalDatum(NewDecFromStringForTest("1.5"))
+	dec, clamped, err = d.ToUnsignedDecimal(sc, 10, 2)
+	require.NoError(t, err)
+	require.False(t, clamped)
+	require.Equal(t, "1.50", dec.String())
+}
+
+func TestToDecimalStrict(t *testing.T) {
+	t.Parallel()
+	strict := new(stmtctx.StatementContext)
+	warn := new(stmtctx.StatementContext)
+	warn.TruncateAsWarning = true
+	ignore := new(stmtctx.StatementContext)
+	ignore.IgnoreTruncate = true
+
+	tests := []struct {
+		d   Datum
+		val string
+	}{
+		{NewStringDatum("hello"), "0"},
+		{NewStringDatum(""), "0"},
+		{NewStringDatum("12abc"), "12"},
+		{NewBytesDatum([]byte("1.5x")), "1.5"},
+	}
+	for _, tt := range tests {
+		_, err := tt.d.ToDecimalStrict(strict)
+		require.True(t, ErrTruncatedWrongVal.Equal(err), "%v", tt.d)
+		require.Contains(t, err.Error(), "Truncated incorrect DECIMAL value")
+
+		// The lenient modes keep the behavior of ToDecimal.
+		dec, err := tt.d.ToDecimalStrict(ignore)
+		require.NoError(t, err)
+		require.Equal(t, tt.val, dec.String())
+		dec, err = tt.d.ToDecimalStrict(warn)
+		require.NoError(t, err)
+		require.Equal(t, tt.val, dec.String())
+	}
+	require.Len(t, warn.GetWarnings(), len(tests))
+	require.True(t, ErrTruncatedWrongVal.Equal(warn.GetWarnings()[0].Err))
+	require.Len(t, ignore.GetWarnings(), 0)
+
+	for _, d := range []Datum{NewStringDatum(" 1.5 "), NewStringDatum("1e2"), NewIntDatum(3), NewFloat64Datum(0.5)} {
+		dec, err := d.ToDecimalStrict(strict)
+		require.NoError(t, err)
+		expected, err := d.ToDecimal(strict)
+		require.NoError(t, err)
+		require.Equal(t, 0, expected.Compare(dec))
+	}
+}
+
+func TestToUintStrict(t *testing.T) {
+	t.Parallel()
+	strict := new(stmtctx.StatementContext)
+	lenient := new(stmtctx.StatementContext)
+	lenient.TruncateAsWarning = true
+
+	tests := []struct {
+		d   Datum
+		val uint64
+	}{
+		{NewIntDatum(7), 7},
+		{NewUintDatum(math.MaxUint64), math.MaxUint64},
+		{NewDecimalDatum(NewDecFromStringForTest("42.000")), 42},
+		{NewStringDatum("3"), 3},
+	}
+	for _, tt := range tests {
+		v, err := tt.d.ToUintStrict(strict)
+		require.NoError(t, err)
+		require.Equal(t, tt.val, v)
+	}
+
+	// A fractional source errors in strict mode, and is truncated with a warning otherwise.
+	for _, d := range []Datum{NewFloat64Datum(1.9), NewDecimalDatum(NewDecFromStringForTest("1.9"))} {
+		_, err := d.ToUintStrict(strict)
+		require.True(t, ErrTruncatedWrongVal.Equal(err), "%v", d)
+
+		v, err := d.ToUintStrict(lenient)
+		require.NoError(t, err)
+		require.Equal(t, uint64(1), v)
+	}
+	require.Len(t, lenient.GetWarnings(), 2)
+
+	// A negative source always errors.
+	for _, d := range []Datum{NewIntDatum(-1), NewFloat64Datum(-0.5), NewDecimalDatum(NewDecFromStringForTest("-3"))} {
+		_, err := d.ToUintStrict(strict)
+		require.True(t, ErrOverflow.Equal(err), "%v", d)
+		_, err = d.ToUintStrict(lenient)
+		require.True(t, ErrOverflow.Equal(err), "%v", d)
+	}
+}
+
 func TestToJSON(t *testing.T) {
 	t.Parallel()
 	ft := NewFieldType(mysql.TypeJSON)
@@ -258,6 +395,125 @@ func TestToJSON(t *testing.T) {
 	}
 }
 
+func TestConvertToJSONOpaque(t *testing.T) {
+	t.Parallel()
+	sc := new(stmtctx.StatementContext)
+	blob := NewFieldTypeWithCollation(mysql.TypeBlob, charset.CollationBin, 0)
+	varbinary := NewFieldTypeWithCollation(mysql.TypeVarchar, charset.CollationBin, 10)
+	tests := []struct {
+		datum    Datum
+		source   *FieldType
+		tp       byte
+		expected string
+	}{
+		{NewBytesDatum([]byte{0xca, 0xfe}), blob, mysql.TypeBlob, `"base64:type252:yv4="`},
+		{NewStringDatum("{}"), varbinary, mysql.TypeVarchar, `"base64:type15:e30="`},
+		{NewBytesDatum([]byte{}), varbinary, mysql.TypeVarchar, `"base64:type15:"`},
+		{NewBinaryLiteralDatum([]byte{0xca, 0xfe}), NewFieldType(mysql.TypeVarString), mysql.TypeVarchar, `"base64:type15:yv4="`},
+		{NewMysqlBitDatum([]byte{0x05}), NewFieldType(mysql.TypeBit), mysql.TypeBit, `"base64:type16:BQ=="`},
+	}
+	for _, tt := range tests {
+		ret, err := tt.datum.ConvertToJSONOpaque(sc, tt.source)
+		require.NoError(t, err)
+		require.Equal(t, KindMysqlJSON, ret.Kind())
+		j := ret.GetMysqlJSON()
+		require.Equal(t, json.TypeCodeOpaque, j.TypeCode)
+		require.Equal(t, tt.expected, j.String())
+
+		// The type tag and the data round-trip.
+		opaque := j.GetOpaque()
+		require.Equal(t, tt.tp, opaque.TypeCode)
+		require.Equal(t, tt.datum.GetBytes(), opaque.Buf)
+
+		// ConvertTo never builds an opaque JSON.
+		ret, err = tt.datum.ConvertTo(sc, NewFieldType(mysql.TypeJSON))
+		if err == nil {
+			require.NotEqual(t, json.TypeCodeOpaque, ret.GetMysqlJSON().TypeCode)
+		}
+	}
+
+	// A non-binary string is parsed as JSON text.
+	utf8 := NewFieldTypeWithCollation(mysql.TypeVarchar, mysql.DefaultCollationName, 10)
+	ret, err := NewStringDatum(`{"a": 1}`).ConvertToJSONOpaque(sc, utf8)
+	require.NoError(t, err)
+	require.Equal(t, json.TypeCodeObject, ret.GetMysqlJSON().TypeCode)
+	require.Equal(t, `{"a": 1}`, ret.GetMysqlJSON().String())
+}
+
+func TestConvertToJSONBoolean(t *testing.T) {
+	t.Parallel()
+	sc := new(stmtctx.StatementContext)
+	for _, b := range []bool{true, false} {
+		d := NewDatum(b)
+		require.Equal(t, KindInt64, d.Kind())
+		ret, err := d.ConvertToJSONBoolean(sc)
+		require.NoError(t, err)
+		j := ret.GetMysqlJSON()
+		require.Equal(t, json.TypeCodeLiteral, j.TypeCode)
+		require.Equal(t, b, j.GetBool())
+		require.Equal(t, strconv.FormatBool(b), j.String())
+
+		// The JSON boolean is not the JSON number ConvertTo gives for the same datum.
+		num, err := d.ConvertTo(sc, NewFieldType(mysql.TypeJSON))
+		require.NoError(t, err)
+		require.Equal(t, json.TypeCodeInt64, num.GetMysqlJSON().TypeCode)
+		require.NotEqual(t, 0, json.CompareBinary(j, num.GetMysqlJSON()))
+
+		// It round-trips through the JSON text and back to an integer.
+		parsed, err := json.ParseBinaryFromString(j.String())
+		require.NoError(t, err)
+		require.Equal(t, b, parsed.GetBool())
+		i, err := ConvertJSONToInt64(sc, parsed, false)
+		require.NoError(t, err)
+		require.Equal(t, d.GetInt64(), i)
+	}
+
+	// Any non-zero value is true, and NULL stays NULL.
+	d := NewFloat64Datum(0.5)
+	ret, err := d.ConvertToJSONBoolean(sc)
+	require.NoError(t, err)
+	require.True(t, ret.GetMysqlJSON().GetBool())
+	d.SetNull()
+	ret, err = d.ConvertToJSONBoolean(sc)
+	require.NoError(t, err)
+	require.True(t, ret.IsNull())
+}
+
+func TestParseStringAsJSON(t *testing.T) {
+	t.Parallel()
+	sc := new(stmtctx.StatementContext)
+	tests := []struct {
+		input    string
+		typeCode json.TypeCode
+		expected string
+	}{
+		{`{"a":1}`, json.TypeCodeObject, `{"a": 1}`},
+		{`"abc"`, json.TypeCodeString, `"abc"`},
+		{`[1, "x"]`, json.TypeCodeArray, `[1, "x"]`},
+		{`1.5`, json.TypeCodeFloat64, `1.5`},
+		{`null`, json.TypeCodeLiteral, `null`},
+	}
+	for _, tt := range tests {
+		d := NewStringDatum(tt.input)
+		j, err := d.ParseStringAsJSON(sc)
+		require.NoError(t, err)
+		require.Equal(t, tt.typeCode, j.TypeCode)
+		require.Equal(t, tt.expected, j.String())
+	}
+
+	for _, input := range []string{"abc", "", `{"a":1`, `"abc`} {
+		d := NewStringDatum(input)
+		_, err := d.ParseStringAsJSON(sc)
+		require.True(t, json.ErrInvalidJSONText.Equal(err), input)
+	}
+
+	// ToMysqlJSON wraps the text as a JSON string instead.
+	d := NewStringDatum("abc")
+	j, err := d.ToMysqlJSON()
+	require.NoError(t, err)
+	require.Equal(t, `"abc"`, j.String())
+}
+
 func TestIsNull(t *testing.T) {
 	t.Parallel()
 	tests := []struct {
@@ -357,6 +613,45 @@ func TestCloneDatum(t *testing.T) {
 	}
 }
 
+func TestCloneDeep(t *testing.T) {
+	t.Parallel()
+	bytes := []byte("abcd")
+	str := []byte("abcd")
+	d1 := NewBytesDatum(bytes)
+	d2 := NewCollationStringDatum(string(hack.String(str)), "utf8mb4_bin")
+	d3 := NewDecimalDatum(NewDecFromStringForTest("1.23"))
+	d4 := NewTimeDatum(NewTime(FromDate(2023, 1, 2, 3, 4, 5, 0), mysql.TypeDatetime, 0))
+	j := json.CreateBinary("abcd")
+	d5 := NewJSONDatum(j)
+	c1, c2, c3, c4, c5 := d1.CloneDeep(), d2.CloneDeep(), d3.CloneDeep(), d4.CloneDeep(), d5.CloneDeep()
+
+	bytes[0] = 'x'
+	str[0] = 'x'
+	require.Equal(t, []byte("xbcd"), d1.GetBytes())
+	require.Equal(t, []byte("abcd"), c1.GetBytes())
+	require.Equal(t, "xbcd", d2.GetString())
+	require.Equal(t, "abcd", c2.GetString())
+	require.Equal(t, "utf8mb4_bin", c2.Collation())
+
+	require.NoError(t, d3.GetMysqlDecimal().FromString([]byte("4.56")))
+	require.Equal(t, "1.23", c3.GetMysqlDecimal().String())
+	d4.SetMysqlTime(NewTime(FromDate(1999, 1, 1, 0, 0, 0, 0), mysql.TypeDatetime, 0))
+	require.Equal(t, "2023-01-02 03:04:05", c4.GetMysqlTime().String())
+	d5.GetMysqlJSON().Value[len(j.Value)-1] = 'x'
+	require.Equal(t, `"abcd"`, c5.GetMysqlJSON().String())
+
+	// The numeric kinds don't allocate, and don't keep a stale slice.
+	d6 := NewBytesDatum([]byte("abcd"))
+	d6.SetInt64(1)
+	c6 := d6.CloneDeep()
+	require.Nil(t, c6.b)
+	require.Equal(t, int64(1), c6.GetInt64())
+	allocs := testing.AllocsPerRun(10, func() {
+		c6 = d6.CloneDeep()
+	})
+	require.Zero(t, allocs)
+}
+
 func newTypeWithFlag(tp byte, flag uint) *FieldType {
 	t := NewFieldType(tp)
 	t.Flag |= flag
@@ -537,6 +832,123 @@ func TestStringToMysqlBit(t *testing.T) {
 	}
 }
 
+func TestGetBinaryLiteral(t *testing.T) {
+	t.Parallel()
+	for _, v := range []interface{}{NewBinaryLiteralFromUint(0x0102, -1), BitLiteral{1, 2}, HexLiteral{1, 2}} {
+		d := NewDatum(v)
+		require.Equal(t, KindBinaryLiteral, d.Kind())
+		require.Equal(t, charset.CollationBin, d.Collation())
+		require.Equal(t, BinaryLiteral{1, 2}, d.GetBinaryLiteral())
+		require.Equal(t, BinaryLiteral{1, 2}, d.MustGetBinaryLiteral())
+		require.Equal(t, BinaryLiteral{1, 2}, d.GetValue())
+	}
+
+	d := NewMysqlBitDatum(NewBinaryLiteralFromUint(5, 2))
+	require.Equal(t, KindMysqlBit, d.Kind())
+	require.Equal(t, BinaryLiteral{0, 5}, d.GetBinaryLiteral())
+	require.Equal(t, BinaryLiteral{0, 5}, d.GetMysqlBit())
+	require.Equal(t, BinaryLiteral{0, 5}, d.MustGetBinaryLiteral())
+	require.Equal(t, BinaryLiteral{0, 5}, d.GetValue())
+
+	// A string datum can be read as its raw bytes, BIN() and the DDL default values rely on it.
+	d = NewStringDatum("ab")
+	require.Equal(t, BinaryLiteral("ab"), d.GetBinaryLiteral())
+	for _, d := range []Datum{NewStringDatum("ab"), NewBytesDatum([]byte("ab")), NewIntDatum(1), {}} {
+		require.Panics(t, func() { d.MustGetBinaryLiteral() }, "%v", d)
+	}
+}
+
+func TestToStringStrict(t *testing.T) {
+	t.Parallel()
+	d := NewStringDatum("a\xffb")
+
+	sc := new(stmtctx.StatementContext)
+	_, err := d.ToStringStrict(sc, charset.CharsetUTF8MB4)
+	require.True(t, ErrTruncatedWrongValueForField.Equal(err))
+
+	sc = new(stmtctx.StatementContext)
+	sc.TruncateAsWarning = true
+	s, err := d.ToStringStrict(sc, charset.CharsetUTF8MB4)
+	require.NoError(t, err)
+	require.Equal(t, "a?b", s)
+	require.Equal(t, uint16(1), sc.WarningCount())
+
+	s, err = d.ToStringStrict(new(stmtctx.StatementContext), charset.CharsetBin)
+	require.NoError(t, err)
+	require.Equal(t, "a\xffb", s)
+}
+
+func TestToStringTruncateInvalid(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		d         Datum
+		chs       string
+		ret       string
+		truncated bool
+	}{
+		{NewStringDatum("a中b"), charset.CharsetUTF8MB4, "a中b", false},
+		{NewStringDatum("a中\xffb"), charset.CharsetUTF8MB4, "a中", true},
+		{NewStringDatum("\xffab"), charset.CharsetUTF8MB4, "", true},
+		// An incomplete multi-byte character is dropped as a whole.
+		{NewStringDatum("ab中\xe4\xb8"), charset.CharsetUTF8MB4, "ab中", true},
+		{NewStringDatum("abc中d"), charset.CharsetASCII, "abc", true},
+		{NewStringDatum("a中😀b"), charset.CharsetGBK, "a中", true},
+		{NewStringDatum("a中b"), charset.CharsetGBK, "a中b", false},
+		// Every byte sequence is valid in latin1 and binary.
+		{NewStringDatum("a\xffb"), charset.CharsetLatin1, "a\xffb", false},
+		{NewBytesDatum([]byte("a\xffb")), charset.CharsetBin, "a\xffb", false},
+		{NewIntDatum(-12), charset.CharsetASCII, "-12", false},
+	}
+	for _, tt := range tests {
+		s, truncated, err := tt.d.ToStringTruncateInvalid(tt.chs)
+		require.NoError(t, err)
+		require.Equal(t, tt.ret, s, "%v %s", tt.d, tt.chs)
+		require.Equal(t, tt.truncated, truncated, "%v %s", tt.d, tt.chs)
+		require.True(t, utf8.ValidString(s) || tt.chs == charset.CharsetLatin1 || tt.chs == charset.CharsetBin)
+	}
+}
+
+func TestConvertDatumToString(t *testing.T) {
+	t.Parallel()
+	strict := new(stmtctx.StatementContext)
+	tests := []struct {
+		d   Datum
+		chs string
+		ret string
+	}{
+		{NewStringDatum("a中b"), charset.CharsetUTF8MB4, "a中b"},
+		{NewStringDatum("a中b"), charset.CharsetGBK, "a\xd6\xd0b"},
+		{NewStringDatum("café"), charset.CharsetLatin1, "caf\xe9"},
+		{NewIntDatum(-12), charset.CharsetGBK, "-12"},
+		{NewDecimalDatum(NewDecFromStringForTest("1.50")), charset.CharsetLatin1, "1.50"},
+		// Binary strings are untouched.
+		{NewBytesDatum([]byte("a\xffb")), charset.CharsetLatin1, "a\xffb"},
+		{NewCollationStringDatum("中", charset.CollationBin), charset.CharsetGBK, "中"},
+		{NewStringDatum("a中b"), charset.CharsetBin, "a中b"},
+	}
+	for _, tt := range tests {
+		s, err := ConvertDatumToString(strict, tt.d, tt.chs)
+		require.NoError(t, err, "%v %s", tt.d, tt.chs)
+		require.Equal(t, tt.ret, s, "%v %s", tt.d, tt.chs)
+	}
+
+	// Unrepresentable characters error in strict mode, and are replaced otherwise.
+	for _, chs := range []string{charset.CharsetLatin1, charset.CharsetASCII} {
+		_, err := ConvertDatumToString(strict, NewStringDatum("a中b"), chs)
+		require.Error(t, err, chs)
+
+		sc := new(stmtctx.StatementContext)
+		sc.TruncateAsWarning = true
+		s, err := ConvertDatumToString(sc, NewStringDatum("a中b"), chs)
+		require.NoError(t, err, chs)
+		require.Equal(t, "a?b", s, chs)
+		require.Equal(t, uint16(1), sc.WarningCount(), chs)
+	}
+
+	_, err := ConvertDatumToString(strict, Datum{}, charset.CharsetUTF8MB4)
+	require.True(t, ErrWrongValueForType.Equal(err))
+}
+
 func BenchmarkCompareDatum(b *testing.B) {
 	vals, vals1 := prepareCompareDatums()
 	sc := new(stmtctx.StatementContext)
@@ -551,6 +963,42 @@ func BenchmarkCompareDatum(b *testing.B) {
 	}
 }
 
+func BenchmarkCompareDatumInt(b *testing.B) {
+	vals := []Datum{NewIntDatum(-1), NewIntDatum(0), NewIntDatum(math.MaxInt64), NewUintDatum(0), NewUintDatum(math.MaxUint64)}
+	vals1 := []Datum{NewIntDatum(1), NewIntDatum(0), NewIntDatum(math.MinInt64), NewUintDatum(1), NewUintDatum(math.MaxUint64)}
+	sc := new(stmtctx.StatementContext)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for j, v := range vals {
+			_, err := v.Compare(sc, &vals1[j], collate.GetBinaryCollator())
+			if err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
+
+func BenchmarkCompareDatumMixedKinds(b *testing.B) {
+	vals := []Datum{
+		NewIntDatum(1), NewStringDatum("1"), NewDecimalDatum(NewDecFromStringForTest("1.5")),
+		NewStringDatum("1.5"), NewFloat64Datum(1.5), NewDurationDatum(Duration{Duration: time.Hour}), {},
+	}
+	vals1 := []Datum{
+		NewStringDatum("1"), NewIntDatum(1), NewStringDatum("1.5"),
+		NewDecimalDatum(NewDecFromStringForTest("1.5")), NewIntDatum(1), NewStringDatum("01:00:00"), NewIntDatum(1),
+	}
+	sc := new(stmtctx.StatementContext)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for j, v := range vals {
+			_, err := v.Compare(sc, &vals1[j], collate.GetBinaryCollator())
+			if err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
+
 func BenchmarkCompareDatumByReflect(b *testing.B) {
 	vals, vals1 := prepareCompareDatums()
 	b.ResetTimer()
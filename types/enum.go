@@ -62,9 +62,13 @@ func ParseEnum(elems []string, name string, collation string) (Enum, error) {
 
 // ParseEnumName creates a Enum with item name.
 func ParseEnumName(elems []string, name string, collation string) (Enum, error) {
-	ctor := collate.GetCollator(collation)
+	return ParseEnumNameWithCollation(elems, name, collate.GetCollator(collation))
+}
+
+// ParseEnumNameWithCollation creates a Enum with the first item equal to name under collator.
+func ParseEnumNameWithCollation(elems []string, name string, collator collate.Collator) (Enum, error) {
 	for i, n := range elems {
-		if ctor.Compare(n, name) == 0 {
+		if collator.Compare(n, name) == 0 {
 			return Enum{Name: n, Value: uint64(i) + 1}, nil
 		}
 	}
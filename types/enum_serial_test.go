@@ -18,6 +18,7 @@ import (
 	"testing"
 
 	"github.com/pingcap/tidb/parser/mysql"
+	"github.com/pingcap/tidb/parser/terror"
 	"github.com/pingcap/tidb/util/collate"
 	"github.com/stretchr/testify/require"
 )
@@ -82,6 +83,36 @@ func TestEnum(t *testing.T) {
 		}
 	})
 
+	t.Run("ParseEnumNameWithCollation", func(t *testing.T) {
+		elems := []string{"active", "inactive"}
+		tests := []struct {
+			Collation string
+			Name      string
+			Expected  int
+		}{
+			{"utf8mb4_general_ci", "ACTIVE", 1},
+			{"utf8mb4_general_ci", "Inactive ", 2},
+			{"utf8mb4_unicode_ci", "INACTIVE", 2},
+			{"utf8mb4_bin", "active", 1},
+			{"utf8mb4_bin", "ACTIVE", 0},
+			{"utf8mb4_general_ci", "pending", 0},
+			{"utf8mb4_general_ci", "1", 0},
+		}
+
+		for _, test := range tests {
+			e, err := ParseEnumNameWithCollation(elems, test.Name, collate.GetCollator(test.Collation))
+			if test.Expected == 0 {
+				require.Truef(t, terror.ErrorEqual(err, ErrTruncated), "err %v", err)
+				require.Equal(t, Enum{}, e)
+				continue
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, elems[test.Expected-1], e.String())
+			require.Equal(t, float64(test.Expected), e.ToNumber())
+		}
+	})
+
 	t.Run("ParseEnumValue", func(t *testing.T) {
 		tests := []struct {
 			Elems    []string
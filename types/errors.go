@@ -82,6 +82,10 @@ var (
 	ErrSyntax = dbterror.ClassTypes.NewStdErr(mysql.ErrParse, mysql.MySQLErrName[mysql.ErrSyntax])
 	// ErrWrongValue is returned when the input value is in wrong format.
 	ErrWrongValue = dbterror.ClassTypes.NewStdErr(mysql.ErrTruncatedWrongValue, mysql.MySQLErrName[mysql.ErrWrongValue])
+	// ErrTruncatedWrongValueForField is returned when a string contains characters invalid for its charset.
+	// It belongs to the table class and is aliased by table.ErrTruncatedWrongValueForField, it's declared
+	// here to prevent `import cycle not allowed`.
+	ErrTruncatedWrongValueForField = dbterror.ClassTable.NewStd(mysql.ErrTruncatedWrongValueForField)
 	// ErrWrongValueForType is returned when the input value is in wrong format for function.
 	ErrWrongValueForType = dbterror.ClassTypes.NewStdErr(mysql.ErrWrongValueForType, mysql.MySQLErrName[mysql.ErrWrongValueForType])
 	// ErrPartitionStatsMissing is returned when the partition-level stats is missing and the build global-level stats fails.
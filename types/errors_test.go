@@ -48,6 +48,7 @@ func TestError(t *testing.T) {
 		ErrTruncatedWrongVal,
 		ErrInvalidWeekModeFormat,
 		ErrWrongValue,
+		ErrTruncatedWrongValueForField,
 	}
 
 	for _, err := range kvErrs {
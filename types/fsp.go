@@ -89,6 +89,26 @@ func ParseFrac(s string, fsp int8) (v int, overflow bool, err error) {
 	return
 }
 
+// parseFracTruncate is like ParseFrac, but truncates the digits exceeding fsp instead of rounding,
+// so it never overflows. eg: "999" fsp=2 -> 990000.
+func parseFracTruncate(s string, fsp int8) (v int, err error) {
+	fsp, err = CheckFsp(int(fsp))
+	if err != nil {
+		return 0, errors.Trace(err)
+	}
+	if len(s) > int(fsp) {
+		s = s[:fsp]
+	}
+	if len(s) == 0 {
+		return 0, nil
+	}
+	tmp, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return 0, errors.Trace(err)
+	}
+	return int(tmp) * int(math.Pow10(int(MaxFsp)-len(s))), nil
+}
+
 // alignFrac is used to generate alignment frac, like `100` -> `100000` ,`-100` -> `-100000`
 func alignFrac(s string, fsp int) string {
 	sl := len(s)
@@ -16,6 +16,7 @@ package json
 
 import (
 	"bytes"
+	"encoding/base64"
 	"encoding/binary"
 	"encoding/json"
 	"fmt"
@@ -47,6 +48,7 @@ import (
        0x0a |       // uint64
        0x0b |       // double
        0x0c |       // utf8mb4 string
+       0x0f |       // opaque, a value of a MySQL type
 
    value ::=
        object  |
@@ -54,6 +56,7 @@ import (
        literal |
        number  |
        string  |
+       opaque  |
 
    object ::= element-count size key-entry* value-entry* key* value*
 
@@ -97,6 +100,10 @@ import (
                              // field. So we need 1 byte to represent
                              // lengths up to 127, 2 bytes to represent
                              // lengths up to 16383, and so on...
+
+   opaque ::= field-type data-length binary-data
+
+   field-type ::= uint8    // the MySQL type of the value, e.g. MYSQL_TYPE_BLOB
 */
 
 // BinaryJSON represents a binary encoded JSON object.
@@ -130,6 +137,8 @@ func (bj BinaryJSON) marshalTo(buf []byte) ([]byte, error) {
 	switch bj.TypeCode {
 	case TypeCodeString:
 		return marshalStringTo(buf, bj.GetString()), nil
+	case TypeCodeOpaque:
+		return bj.marshalOpaqueTo(buf), nil
 	case TypeCodeLiteral:
 		return marshalLiteralTo(buf, bj.Value[0]), nil
 	case TypeCodeInt64:
@@ -183,6 +192,11 @@ func (bj BinaryJSON) GetFloat64() float64 {
 	return math.Float64frombits(bj.GetUint64())
 }
 
+// GetBool gets the value of a true or false literal.
+func (bj BinaryJSON) GetBool() bool {
+	return bj.Value[0] == LiteralTrue
+}
+
 // GetString gets the string value.
 func (bj BinaryJSON) GetString() []byte {
 	strLen, lenLen := uint64(bj.Value[0]), 1
@@ -192,6 +206,28 @@ func (bj BinaryJSON) GetString() []byte {
 	return bj.Value[lenLen : lenLen+int(strLen)]
 }
 
+// Opaque is a value of a MySQL type which JSON has no counterpart for, e.g. a BLOB.
+type Opaque struct {
+	// TypeCode is the MySQL type of the value, e.g. mysql.TypeBlob.
+	TypeCode byte
+	// Buf is the binary data of the value.
+	Buf []byte
+}
+
+// GetOpaque gets the opaque value.
+func (bj BinaryJSON) GetOpaque() Opaque {
+	dataLen, lenLen := binary.Uvarint(bj.Value[valTypeSize:])
+	start := valTypeSize + lenLen
+	return Opaque{TypeCode: bj.Value[0], Buf: bj.Value[start : start+int(dataLen)]}
+}
+
+// marshalOpaqueTo marshals an opaque value like MySQL does, e.g. "base64:type15:yv4=".
+func (bj BinaryJSON) marshalOpaqueTo(buf []byte) []byte {
+	opaque := bj.GetOpaque()
+	str := fmt.Sprintf("base64:type%d:%s", opaque.TypeCode, base64.StdEncoding.EncodeToString(opaque.Buf))
+	return marshalStringTo(buf, hack.Slice(str))
+}
+
 // GetKeys gets the keys of the object
 func (bj BinaryJSON) GetKeys() BinaryJSON {
 	count := bj.GetElemCount()
@@ -237,6 +273,10 @@ func (bj BinaryJSON) valEntryGet(valEntryOff int) BinaryJSON {
 		}
 		totalLen := uint32(lenLen) + uint32(strLen)
 		return BinaryJSON{TypeCode: tpCode, Value: bj.Value[valOff : valOff+totalLen]}
+	case TypeCodeOpaque:
+		dataLen, lenLen := binary.Uvarint(bj.Value[valOff+valTypeSize:])
+		totalLen := uint32(valTypeSize) + uint32(lenLen) + uint32(dataLen)
+		return BinaryJSON{TypeCode: tpCode, Value: bj.Value[valOff : valOff+totalLen]}
 	}
 	dataSize := endian.Uint32(bj.Value[valOff+dataSizeOff:])
 	return BinaryJSON{TypeCode: tpCode, Value: bj.Value[valOff : valOff+dataSize]}
@@ -442,6 +482,14 @@ func (bj BinaryJSON) HashValue(buf []byte) []byte {
 		} else {
 			buf = append(buf, bj.Value...)
 		}
+	case TypeCodeUint64:
+		// Like TypeCodeInt64, so that int64(3) and uint64(3) hash equal, the raw values of them are
+		// the same too if the conversion is lossy.
+		if bj.GetUint64() == uint64(float64(bj.GetUint64())) {
+			buf = appendBinaryFloat64(buf, float64(bj.GetUint64()))
+		} else {
+			buf = append(buf, bj.Value...)
+		}
 	case TypeCodeArray:
 		elemCount := int(endian.Uint32(bj.Value))
 		for i := 0; i < elemCount; i++ {
@@ -499,6 +547,9 @@ func appendBinary(buf []byte, in interface{}) (TypeCode, []byte, error) {
 	case string:
 		typeCode = TypeCodeString
 		buf = appendBinaryString(buf, x)
+	case Opaque:
+		typeCode = TypeCodeOpaque
+		buf = appendBinaryOpaque(buf, x)
 	case BinaryJSON:
 		typeCode = x.TypeCode
 		buf = append(buf, x.Value...)
@@ -575,6 +626,14 @@ func appendBinaryString(buf []byte, v string) []byte {
 	return buf
 }
 
+func appendBinaryOpaque(buf []byte, v Opaque) []byte {
+	buf = append(buf, v.TypeCode)
+	var lenBuf [binary.MaxVarintLen64]byte
+	lenLen := binary.PutUvarint(lenBuf[:], uint64(len(v.Buf)))
+	buf = append(buf, lenBuf[:lenLen]...)
+	return append(buf, v.Buf...)
+}
+
 func appendBinaryFloat64(buf []byte, v float64) []byte {
 	off := len(buf)
 	buf = appendZero(buf, 8)
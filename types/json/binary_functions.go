@@ -24,6 +24,7 @@ import (
 	"unicode/utf8"
 
 	"github.com/pingcap/errors"
+	"github.com/pingcap/tidb/parser/mysql"
 	"github.com/pingcap/tidb/util/hack"
 	"github.com/pingcap/tidb/util/stringutil"
 )
@@ -50,6 +51,16 @@ func (bj BinaryJSON) Type() string {
 		return "DOUBLE"
 	case TypeCodeString:
 		return "STRING"
+	case TypeCodeOpaque:
+		switch bj.Value[0] {
+		case mysql.TypeTinyBlob, mysql.TypeMediumBlob, mysql.TypeLongBlob, mysql.TypeBlob,
+			mysql.TypeString, mysql.TypeVarString, mysql.TypeVarchar:
+			return "BLOB"
+		case mysql.TypeBit:
+			return "BIT"
+		default:
+			return "OPAQUE"
+		}
 	default:
 		msg := fmt.Sprintf(unknownTypeCodeErrorMsg, bj.TypeCode)
 		panic(msg)
@@ -600,7 +611,7 @@ func (bm *binaryModifier) rebuildTo(buf []byte) ([]byte, TypeCode) {
 	}
 	bj := bm.bj
 	switch bj.TypeCode {
-	case TypeCodeLiteral, TypeCodeInt64, TypeCodeUint64, TypeCodeFloat64, TypeCodeString:
+	case TypeCodeLiteral, TypeCodeInt64, TypeCodeUint64, TypeCodeFloat64, TypeCodeString, TypeCodeOpaque:
 		return append(buf, bj.Value...), bj.TypeCode
 	}
 	docOff := len(buf)
@@ -744,6 +755,8 @@ func CompareBinary(left, right BinaryJSON) int {
 			}
 		case TypeCodeString:
 			cmp = bytes.Compare(left.GetString(), right.GetString())
+		case TypeCodeOpaque:
+			cmp = bytes.Compare(left.GetOpaque().Buf, right.GetOpaque().Buf)
 		case TypeCodeArray:
 			leftCount := left.GetElemCount()
 			rightCount := right.GetElemCount()
@@ -951,6 +964,70 @@ func mergeBinaryObject(objects []BinaryJSON) BinaryJSON {
 	return binaryObject
 }
 
+// KV is a key-value pair used to build a JSON object.
+type KV struct {
+	Key   string
+	Value BinaryJSON
+}
+
+// BuildJSONObject builds a JSON object from pairs, a duplicated key keeps its last value.
+// The keys are sorted as in any other BinaryJSON object.
+func BuildJSONObject(pairs []KV) (BinaryJSON, error) {
+	keys, values := dedupKVs(pairs)
+	sortIdx := make([]int, len(keys))
+	for i := range sortIdx {
+		sortIdx[i] = i
+	}
+	sort.Slice(sortIdx, func(i, j int) bool {
+		return bytes.Compare(keys[sortIdx[i]], keys[sortIdx[j]]) < 0
+	})
+	sortedKeys := make([][]byte, len(keys))
+	sortedValues := make([]BinaryJSON, len(keys))
+	for i, idx := range sortIdx {
+		sortedKeys[i], sortedValues[i] = keys[idx], values[idx]
+	}
+	return buildBinaryObject(sortedKeys, sortedValues)
+}
+
+// MarshalJSONObjectInOrder marshals pairs to the text of a JSON object, keeping the keys in
+// insertion order rather than the sorted order of BinaryJSON. A duplicated key keeps its first
+// position and its last value.
+func MarshalJSONObjectInOrder(pairs []KV) ([]byte, error) {
+	keys, values := dedupKVs(pairs)
+	buf := []byte{'{'}
+	for i, key := range keys {
+		if i != 0 {
+			buf = append(buf, ", "...)
+		}
+		buf = marshalStringTo(buf, key)
+		buf = append(buf, ": "...)
+		var err error
+		buf, err = values[i].marshalTo(buf)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+	}
+	return append(buf, '}'), nil
+}
+
+// dedupKVs splits pairs into keys and values in the order the keys first appear, a duplicated
+// key keeps its last value.
+func dedupKVs(pairs []KV) ([][]byte, []BinaryJSON) {
+	keyValMap := make(map[string]BinaryJSON, len(pairs))
+	keys := make([][]byte, 0, len(pairs))
+	for _, pair := range pairs {
+		if _, ok := keyValMap[pair.Key]; !ok {
+			keys = append(keys, []byte(pair.Key))
+		}
+		keyValMap[pair.Key] = pair.Value
+	}
+	values := make([]BinaryJSON, len(keys))
+	for i, key := range keys {
+		values[i] = keyValMap[string(key)]
+	}
+	return keys, values
+}
+
 // PeekBytesAsJSON trys to peek some bytes from b, until
 // we can deserialize a JSON from those bytes.
 func PeekBytesAsJSON(b []byte) (n int, err error) {
@@ -968,6 +1045,11 @@ func PeekBytesAsJSON(b []byte) (n int, err error) {
 	case TypeCodeString:
 		strLen, lenLen := binary.Uvarint(b[valTypeSize:])
 		return valTypeSize + int(strLen) + lenLen, nil
+	case TypeCodeOpaque:
+		if len(b) > valTypeSize+1 {
+			dataLen, lenLen := binary.Uvarint(b[valTypeSize+1:])
+			return valTypeSize + 1 + lenLen + int(dataLen), nil
+		}
 	case TypeCodeInt64, TypeCodeUint64, TypeCodeFloat64:
 		n = valTypeSize + 8
 		return
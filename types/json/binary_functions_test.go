@@ -51,3 +51,31 @@ func BenchmarkMergeBinary(b *testing.B) {
 		_ = MergeBinary([]BinaryJSON{valueA, valueB})
 	}
 }
+
+func TestBuildJSONObject(t *testing.T) {
+	t.Parallel()
+
+	pairs := []KV{
+		{"b", CreateBinary(int64(1))},
+		{"a", CreateBinary("x")},
+		{"c", CreateBinary(true)},
+		{"a", CreateBinary("y")},
+	}
+	obj, err := BuildJSONObject(pairs)
+	require.NoError(t, err)
+	require.Equal(t, `{"a": "y", "b": 1, "c": true}`, obj.String())
+	val, ok := obj.objectSearchKey([]byte("a"))
+	require.True(t, ok)
+	require.Equal(t, `"y"`, val.String())
+
+	text, err := MarshalJSONObjectInOrder(pairs)
+	require.NoError(t, err)
+	require.Equal(t, `{"b": 1, "a": "y", "c": true}`, string(text))
+
+	obj, err = BuildJSONObject(nil)
+	require.NoError(t, err)
+	require.Equal(t, `{}`, obj.String())
+	text, err = MarshalJSONObjectInOrder(nil)
+	require.NoError(t, err)
+	require.Equal(t, `{}`, string(text))
+}
@@ -15,11 +15,14 @@
 package json
 
 import (
+	"bytes"
+	"encoding/base64"
 	"fmt"
 	"math"
 	"strings"
 	"testing"
 
+	"github.com/pingcap/tidb/parser/mysql"
 	"github.com/stretchr/testify/require"
 )
 
@@ -414,6 +417,88 @@ func TestBinaryJSONCopy(t *testing.T) {
 	}
 }
 
+func TestBinaryJSONHashValue(t *testing.T) {
+	t.Parallel()
+	// The hashes of int64, float64 and string JSON values are pinned, hash join and hash
+	// aggregation keys built from them must not change.
+	three := []byte{0, 0, 0, 0, 0, 0, 0x08, 0x40}
+	lossy := int64(1<<62 + 1)
+	tests := []struct {
+		bj   BinaryJSON
+		hash []byte
+	}{
+		{CreateBinary(int64(3)), three},
+		{CreateBinary(float64(3)), three},
+		{CreateBinary(lossy), CreateBinary(lossy).Value},
+		{CreateBinary(float64(-2.5)), []byte{0, 0, 0, 0, 0, 0, 0x04, 0xc0}},
+		{CreateBinary("abc"), []byte{3, 'a', 'b', 'c'}},
+		{CreateBinary(""), []byte{0}},
+		{CreateBinary(true), []byte{LiteralTrue}},
+		{CreateBinary(nil), []byte{LiteralNil}},
+		{CreateBinary([]interface{}{int64(3), "a"}), append(append([]byte{}, three...), 1, 'a')},
+		{CreateBinary(map[string]interface{}{"k": float64(3)}), append([]byte{'k'}, three...)},
+		// uint64 values hash like int64 ones.
+		{CreateBinary(uint64(3)), three},
+		{CreateBinary(uint64(1<<63 + 1)), CreateBinary(uint64(1<<63 + 1)).Value},
+	}
+	for _, tt := range tests {
+		require.Equal(t, tt.hash, tt.bj.HashValue(nil), tt.bj.String())
+		// The hash is appended to the buffer.
+		require.Equal(t, append([]byte{0xff}, tt.hash...), tt.bj.HashValue([]byte{0xff}), tt.bj.String())
+	}
+}
+
+func TestBinaryJSONOpaque(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		opaque   Opaque
+		str      string
+		typeName string
+	}{
+		{Opaque{TypeCode: mysql.TypeVarchar, Buf: []byte{0xca, 0xfe}}, `"base64:type15:yv4="`, "BLOB"},
+		{Opaque{TypeCode: mysql.TypeBlob, Buf: []byte{}}, `"base64:type252:"`, "BLOB"},
+		{Opaque{TypeCode: mysql.TypeBit, Buf: []byte{0x05}}, `"base64:type16:BQ=="`, "BIT"},
+		{Opaque{TypeCode: mysql.TypeGeometry, Buf: bytes.Repeat([]byte{0xff}, 200)}, `"base64:type255:` + base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{0xff}, 200)) + `"`, "OPAQUE"},
+	}
+	for _, tt := range tests {
+		bj := CreateBinary(tt.opaque)
+		require.Equal(t, TypeCodeOpaque, bj.TypeCode)
+		require.Equal(t, tt.opaque, bj.GetOpaque())
+		require.Equal(t, tt.str, bj.String())
+		require.Equal(t, tt.typeName, bj.Type())
+
+		n, err := PeekBytesAsJSON(append([]byte{bj.TypeCode}, bj.Value...))
+		require.NoError(t, err)
+		require.Equal(t, 1+len(bj.Value), n)
+
+		// The type tag round-trips inside an array and an object.
+		arr := CreateBinary([]interface{}{tt.opaque, "a"})
+		require.Equal(t, "["+tt.str+`, "a"]`, arr.String())
+		pe, err := ParseJSONPathExpr("$[0]")
+		require.NoError(t, err)
+		elem, found := arr.Extract([]PathExpression{pe})
+		require.True(t, found)
+		require.Equal(t, tt.opaque, elem.GetOpaque())
+
+		obj := CreateBinary(map[string]interface{}{"a": tt.opaque, "b": int64(1)})
+		pe, err = ParseJSONPathExpr("$.a")
+		require.NoError(t, err)
+		elem, found = obj.Extract([]PathExpression{pe})
+		require.True(t, found)
+		require.Equal(t, tt.opaque, elem.GetOpaque())
+		require.Equal(t, 0, CompareBinary(bj, elem))
+	}
+
+	small := CreateBinary(Opaque{TypeCode: mysql.TypeBlob, Buf: []byte{1}})
+	large := CreateBinary(Opaque{TypeCode: mysql.TypeBlob, Buf: []byte{2}})
+	require.Equal(t, -1, CompareBinary(small, large))
+	require.Equal(t, 1, CompareBinary(large, small))
+	// A BLOB has the highest precedence of all JSON types.
+	require.Equal(t, 1, CompareBinary(small, CreateBinary("z")))
+	require.Equal(t, -1, CompareBinary(CreateBinary(Opaque{TypeCode: mysql.TypeBit, Buf: []byte{2}}), small))
+}
+
 func TestGetKeys(t *testing.T) {
 	t.Parallel()
 
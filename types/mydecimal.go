@@ -16,6 +16,7 @@ package types
 
 import (
 	"math"
+	"math/big"
 	"strconv"
 	"strings"
 
@@ -52,8 +53,16 @@ const (
 
 	DivFracIncr = 4
 
+	// ModeHalfUp rounds normally, a tie is rounded away from zero, e.g. 2.5 to 3 and -2.5 to -3.
+	ModeHalfUp RoundMode = 5
 	// ModeHalfEven rounds normally.
-	ModeHalfEven RoundMode = 5
+	//
+	// Deprecated: despite its name it rounds a tie away from zero, it's an alias of ModeHalfUp.
+	// Use ModeHalfToEven for rounding a tie to the even neighbour.
+	ModeHalfEven = ModeHalfUp
+	// ModeHalfToEven rounds half to even, also known as banker's rounding, e.g. 2.5 to 2 and
+	// -2.5 to -2.
+	ModeHalfToEven RoundMode = 1
 	// Truncate just truncates the decimal.
 	ModeTruncate RoundMode = 10
 	// Ceiling is not supported now.
@@ -252,6 +261,26 @@ func (d *MyDecimal) IsNegative() bool {
 	return d.negative
 }
 
+// Clone returns a copy of the decimal. MyDecimal holds its digits in an array rather than
+// a slice, so the copy shares nothing with d and may be mutated independently.
+func (d *MyDecimal) Clone() *MyDecimal {
+	to := *d
+	return &to
+}
+
+// NegInPlace negates the decimal in place, a zero decimal stays non-negative like DecimalNeg.
+func (d *MyDecimal) NegInPlace() {
+	if d.IsZero() {
+		return
+	}
+	d.negative = !d.negative
+}
+
+// SetNegative sets the sign of the decimal, a zero decimal is never negative so it stays unchanged.
+func (d *MyDecimal) SetNegative(neg bool) {
+	d.negative = neg && !d.IsZero()
+}
+
 // GetDigitsFrac returns the digitsFrac.
 func (d *MyDecimal) GetDigitsFrac() int8 {
 	return d.digitsFrac
@@ -270,6 +299,21 @@ func (d *MyDecimal) String() string {
 	return string(tmp.ToString())
 }
 
+// StringFixed returns the decimal string representation with exactly decimals
+// digits after the decimal point, like strconv.FormatFloat with 'f'. Extra digits
+// are rounded half away from zero as MySQL does for DECIMAL, so "1.005" gives
+// "1.01" with 2 decimals; missing digits are padded with zeros. A negative
+// decimals is treated as 0.
+func (d *MyDecimal) StringFixed(decimals int) string {
+	if decimals < 0 {
+		decimals = 0
+	}
+	tmp := *d
+	err := tmp.Round(&tmp, decimals, ModeHalfUp)
+	terror.Log(errors.Trace(err))
+	return string(tmp.ToString())
+}
+
 func (d *MyDecimal) stringSize() int {
 	// sign, zero integer and dot.
 	return int(d.digitsInt + d.digitsFrac + 3)
@@ -394,15 +438,26 @@ func (d *MyDecimal) ToString() (str []byte) {
 
 // FromString parses decimal from string.
 func (d *MyDecimal) FromString(str []byte) error {
+	_, err := d.FromBytes(str)
+	return err
+}
+
+// FromBytes parses decimal from a byte buffer like FromString, and also returns the number of
+// leading bytes of b that form the number, including leading spaces and the exponent.
+func (d *MyDecimal) FromBytes(b []byte) (int, error) {
+	str := b
+	// offset is the number of bytes trimmed from the front of b.
+	var offset int
 	for i := 0; i < len(str); i++ {
 		if !isSpace(str[i]) {
 			str = str[i:]
+			offset = i
 			break
 		}
 	}
 	if len(str) == 0 {
 		*d = zeroMyDecimal
-		return ErrBadNumber
+		return 0, ErrBadNumber
 	}
 	switch str[0] {
 	case '-':
@@ -410,6 +465,7 @@ func (d *MyDecimal) FromString(str []byte) error {
 		fallthrough
 	case '+':
 		str = str[1:]
+		offset++
 	}
 	var strIdx int
 	for strIdx < len(str) && isDigit(str[strIdx]) {
@@ -430,8 +486,9 @@ func (d *MyDecimal) FromString(str []byte) error {
 	}
 	if digitsInt+digitsFrac == 0 {
 		*d = zeroMyDecimal
-		return ErrBadNumber
+		return 0, ErrBadNumber
 	}
+	consumed := offset + endIdx
 	wordsInt := digitsToWords(digitsInt)
 	wordsFrac := digitsToWords(digitsFrac)
 	wordsInt, wordsFrac, err := fixWordCntError(wordsInt, wordsFrac)
@@ -486,6 +543,9 @@ func (d *MyDecimal) FromString(str []byte) error {
 	if endIdx+1 <= len(str) {
 		if str[endIdx] == 'e' || str[endIdx] == 'E' {
 			exponent, err1 := strToInt(string(str[endIdx+1:]))
+			if expEnd := exponentEnd(str, endIdx+1); expEnd > 0 {
+				consumed = offset + expEnd
+			}
 			if err1 != nil {
 				err = errors.Cause(err1)
 				if err != ErrTruncated {
@@ -531,7 +591,27 @@ func (d *MyDecimal) FromString(str []byte) error {
 		d.negative = false
 	}
 	d.resultFrac = d.digitsFrac
-	return err
+	return consumed, err
+}
+
+// exponentEnd returns the end of the exponent that strToInt parses from str[start:], or 0 if
+// there are no exponent digits.
+func exponentEnd(str []byte, start int) int {
+	i := start
+	for i < len(str) && isSpace(str[i]) {
+		i++
+	}
+	if i < len(str) && (str[i] == '-' || str[i] == '+') {
+		i++
+	}
+	digitsStart := i
+	for i < len(str) && isDigit(str[i]) {
+		i++
+	}
+	if i == digitsStart {
+		return 0
+	}
+	return i
 }
 
 // Shift shifts decimal digits in given number (with rounding if it need), shift > 0 means shift to left shift,
@@ -801,8 +881,9 @@ func (d *MyDecimal) doMiniRightShift(shift, beg, end int) {
 //
 //    to			- result buffer. d == to is allowed
 //    frac			- to what position after fraction point to round. can be negative!
-//    roundMode		- round to nearest even or truncate
-// 			ModeHalfEven rounds normally.
+//    roundMode		- round half up, half to even or truncate
+// 			ModeHalfUp rounds normally.
+// 			ModeHalfToEven rounds a tie to the even neighbour.
 // 			Truncate just truncates the decimal.
 //
 // NOTES
@@ -812,6 +893,13 @@ func (d *MyDecimal) doMiniRightShift(shift, beg, end int) {
 // RETURN VALUE
 //  eDecOK/eDecTruncated
 func (d *MyDecimal) Round(to *MyDecimal, frac int, roundMode RoundMode) (err error) {
+	if roundMode == ModeHalfToEven {
+		// Rounding half to even either rounds normally or truncates.
+		roundMode = ModeTruncate
+		if d.roundsUpHalfEven(frac) {
+			roundMode = ModeHalfUp
+		}
+	}
 	// wordsFracTo is the number of fraction words in buffer.
 	wordsFracTo := (frac + 1) / digitsPerWord
 	if frac > 0 {
@@ -871,7 +959,7 @@ func (d *MyDecimal) Round(to *MyDecimal, frac int, roundMode RoundMode) (err err
 				}
 				idx--
 			}
-		case ModeHalfEven:
+		case ModeHalfUp:
 			digAfterScale := d.wordBuf[toIdx+1] / digMask // the first digit after scale.
 			// If first digit after scale is 5 and round even, do increment if digit at scale is odd.
 			doInc = (digAfterScale > 5) || (digAfterScale == 5)
@@ -985,6 +1073,61 @@ func (d *MyDecimal) Round(to *MyDecimal, frac int, roundMode RoundMode) (err err
 	return
 }
 
+// digitAt returns the digit of d at the power of ten pos, so 0 is the units
+// digit and -1 the first fraction digit.
+func (d *MyDecimal) digitAt(pos int) int32 {
+	wordsInt := digitsToWords(int(d.digitsInt))
+	if pos >= 0 {
+		idx := wordsInt - 1 - pos/digitsPerWord
+		if idx < 0 {
+			return 0
+		}
+		return d.wordBuf[idx] / powers10[pos%digitsPerWord] % 10
+	}
+	if -pos > int(d.digitsFrac) {
+		return 0
+	}
+	idx := wordsInt + (-pos-1)/digitsPerWord
+	return d.wordBuf[idx] / powers10[digitsPerWord-1-(-pos-1)%digitsPerWord] % 10
+}
+
+// roundsUpHalfEven reports whether rounding d half to even at frac digits
+// increases its magnitude.
+func (d *MyDecimal) roundsUpHalfEven(frac int) bool {
+	if frac >= int(d.digitsFrac) {
+		return false
+	}
+	switch first := d.digitAt(-frac - 1); {
+	case first > 5:
+		return true
+	case first < 5:
+		return false
+	}
+	for pos := -frac - 2; pos >= -int(d.digitsFrac); pos-- {
+		if d.digitAt(pos) != 0 {
+			return true
+		}
+	}
+	return d.digitAt(-frac)%2 == 1
+}
+
+// RoundToSignificant rounds the decimal to n significant digits rather than decimal places,
+// e.g, 123.456 to 2 significant digits is 120, and 0.001234 is 0.0012.
+func (d *MyDecimal) RoundToSignificant(n int, to *MyDecimal) error {
+	if n <= 0 {
+		return ErrBadNumber
+	}
+	start, end := d.digitBounds()
+	if start == end {
+		*to = *d
+		return nil
+	}
+	// intDigits is the number of digits before the decimal point counting from the most
+	// significant non-zero digit, it is negative when there are zeros after the point.
+	intDigits := digitsToWords(int(d.digitsInt))*digitsPerWord - start
+	return d.Round(to, n-intDigits, ModeHalfUp)
+}
+
 // FromInt sets the decimal value from int64.
 func (d *MyDecimal) FromInt(val int64) *MyDecimal {
 	var uVal uint64
@@ -1364,6 +1507,16 @@ func (d *MyDecimal) PrecisionAndFrac() (precision, frac int) {
 	return
 }
 
+// LeadingZeros returns the count of insignificant leading zero digits in the integer part of d,
+// which is the precision of the integer part d holds minus its significant integer digits, e.g.
+// 0.05 -> 1, 100 -> 0, and 5.00 read from a DECIMAL(10,2) by FromBin -> 7. The integer part
+// parsed by FromString is as wide as written, and one made by FromInt or an arithmetic operation
+// is a multiple of 9 digits wide.
+func (d *MyDecimal) LeadingZeros() int {
+	_, digitsInt := d.removeLeadingZeros()
+	return int(d.digitsInt) - digitsInt
+}
+
 // IsZero checks whether it's a zero decimal.
 func (d *MyDecimal) IsZero() bool {
 	isZero := true
@@ -1538,6 +1691,97 @@ func (d *MyDecimal) Compare(to *MyDecimal) int {
 	return 1
 }
 
+// CompareExact compares d and other like Compare, exact reports whether no precision was dropped
+// when aligning them, i.e. neither of them holds non-zero digits beyond its result fraction, which
+// are hidden by String, e.g. the quotient of 1/3. Decimals of different scales like 1.5 and 1.50
+// are aligned by padding zeros, so they are still compared exactly.
+func (d *MyDecimal) CompareExact(other *MyDecimal) (result int, exact bool) {
+	return d.Compare(other), !d.hasHiddenDigits() && !other.hasHiddenDigits()
+}
+
+// hasHiddenDigits returns whether d holds non-zero digits beyond its result fraction.
+func (d *MyDecimal) hasHiddenDigits() bool {
+	if d.digitsFrac <= d.resultFrac {
+		return false
+	}
+	var truncated MyDecimal
+	if err := d.Round(&truncated, int(d.resultFrac), ModeTruncate); err != nil {
+		return true
+	}
+	return d.Compare(&truncated) != 0
+}
+
+// PreparedDecimal is a decimal prepared for being compared repeatedly, e.g. a constant in a
+// filter. It keeps the significant words of the decimal, so that each comparison only needs to
+// scan the other operand.
+type PreparedDecimal struct {
+	negative bool
+	// intWords are the integer words without the leading zero words.
+	intWords []int32
+	// fracWords are the fraction words without the trailing zero words.
+	fracWords []int32
+}
+
+// NewPreparedDecimal prepares d for comparisons. Later changes of d don't affect the result.
+func NewPreparedDecimal(d *MyDecimal) *PreparedDecimal {
+	intWords, fracWords := d.significantWords()
+	return &PreparedDecimal{
+		negative:  d.negative,
+		intWords:  append([]int32(nil), intWords...),
+		fracWords: append([]int32(nil), fracWords...),
+	}
+}
+
+// CompareTo compares the prepared decimal with other, the result is the same as Compare.
+func (p *PreparedDecimal) CompareTo(other *MyDecimal) int {
+	if p.negative != other.negative {
+		if p.negative {
+			return -1
+		}
+		return 1
+	}
+	intWords, fracWords := other.significantWords()
+	cmp := compareSignificantWords(p.intWords, p.fracWords, intWords, fracWords)
+	if p.negative {
+		return -cmp
+	}
+	return cmp
+}
+
+// significantWords returns the integer words without the leading zero words and the fraction
+// words without the trailing zero words.
+func (d *MyDecimal) significantWords() (intWords, fracWords []int32) {
+	wordsInt := digitsToWords(int(d.digitsInt))
+	wordsFrac := digitsToWords(int(d.digitsFrac))
+	start := 0
+	for start < wordsInt && d.wordBuf[start] == 0 {
+		start++
+	}
+	end := wordsInt + wordsFrac
+	for end > wordsInt && d.wordBuf[end-1] == 0 {
+		end--
+	}
+	return d.wordBuf[start:wordsInt], d.wordBuf[wordsInt:end]
+}
+
+// compareSignificantWords compares the magnitudes of two decimals given their significant words.
+func compareSignificantWords(int1, frac1, int2, frac2 []int32) int {
+	if len(int1) != len(int2) {
+		return CompareInt64(int64(len(int1)), int64(len(int2)))
+	}
+	for i := range int1 {
+		if int1[i] != int2[i] {
+			return CompareInt64(int64(int1[i]), int64(int2[i]))
+		}
+	}
+	for i := 0; i < len(frac1) && i < len(frac2); i++ {
+		if frac1[i] != frac2[i] {
+			return CompareInt64(int64(frac1[i]), int64(frac2[i]))
+		}
+	}
+	return CompareInt64(int64(len(frac1)), int64(len(frac2)))
+}
+
 // DecimalNeg reverses decimal's sign.
 func DecimalNeg(from *MyDecimal) *MyDecimal {
 	to := *from
@@ -1561,6 +1805,109 @@ func DecimalAdd(from1, from2, to *MyDecimal) error {
 	return err
 }
 
+// DecimalSum accumulates decimals without rounding the intermediate sums. The running sum is
+// kept in a MyDecimal while it fits in its 81 digits, and is widened to a big.Int once it doesn't,
+// so it stays exact either way and is only rounded to the target precision in Result.
+// The zero value is an empty sum ready to use.
+type DecimalSum struct {
+	sum MyDecimal
+	// buf is the scratch buffer Add adds into, so adding doesn't allocate while the sum fits.
+	buf MyDecimal
+	// wide is the sum * 10^wideFrac once it doesn't fit in sum, it's nil before that.
+	wide     *big.Int
+	wideFrac int
+	// resultFrac is the largest resultFrac of the added decimals.
+	resultFrac int8
+}
+
+// Add adds d to the sum.
+func (s *DecimalSum) Add(d *MyDecimal) error {
+	s.resultFrac = myMaxInt8(s.resultFrac, d.resultFrac)
+	if s.wide == nil {
+		err := DecimalAdd(&s.sum, d, &s.buf)
+		if err == nil {
+			s.sum = s.buf
+			return nil
+		}
+		if err != ErrTruncated && err != ErrOverflow {
+			return err
+		}
+		// The new sum doesn't fit in a MyDecimal, s.sum is unchanged, so widen it.
+		s.wideFrac = int(s.sum.digitsFrac)
+		s.wide = s.sum.toScaledBigInt(s.wideFrac)
+	}
+	if frac := int(d.digitsFrac); frac > s.wideFrac {
+		s.wide.Mul(s.wide, new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(frac-s.wideFrac)), nil))
+		s.wideFrac = frac
+	}
+	s.wide.Add(s.wide, d.toScaledBigInt(s.wideFrac))
+	return nil
+}
+
+// Frac returns the largest scale of the added decimals, at most mysql.MaxDecimalScale, which is
+// the scale of their SUM. Result(mysql.MaxDecimalWidth, s.Frac()) is the SUM as MySQL computes it,
+// and reports ErrOverflow beyond the DECIMAL(65,30) limits.
+func (s *DecimalSum) Frac() int {
+	return myMin(int(s.resultFrac), mysql.MaxDecimalScale)
+}
+
+// Result rounds the sum to DECIMAL(prec, frac). It returns ErrOverflow along with the max or min
+// value if the sum doesn't fit.
+func (s *DecimalSum) Result(prec, frac int) (*MyDecimal, error) {
+	if s.wide != nil {
+		return s.wideResult(prec, frac)
+	}
+	res := new(MyDecimal)
+	if err := s.sum.Round(res, frac, ModeHalfUp); err != nil && err != ErrTruncated {
+		return nil, err
+	}
+	if p, f := res.PrecisionAndFrac(); !res.IsZero() && p-f > prec-frac {
+		return NewMaxOrMinDec(res.IsNegative(), prec, frac), ErrOverflow
+	}
+	return res, nil
+}
+
+// wideResult is Result for a sum widened to a big.Int, rounding half away from zero like
+// ModeHalfUp does.
+func (s *DecimalSum) wideResult(prec, frac int) (*MyDecimal, error) {
+	r := new(big.Int).Abs(s.wide)
+	if frac < s.wideFrac {
+		div := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(s.wideFrac-frac)), nil)
+		var rem big.Int
+		r.QuoRem(r, div, &rem)
+		if rem.Lsh(&rem, 1).Cmp(div) >= 0 {
+			r.Add(r, big.NewInt(1))
+		}
+	} else if frac > s.wideFrac {
+		r.Mul(r, new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(frac-s.wideFrac)), nil))
+	}
+	negative := s.wide.Sign() < 0
+	if r.Sign() != 0 && len(r.Text(10)) > prec {
+		return NewMaxOrMinDec(negative, prec, frac), ErrOverflow
+	}
+	if negative {
+		r.Neg(r)
+	}
+	return FromBigInt(r, frac)
+}
+
+// DecimalAccumulator sums decimals like SUM does, it's a DecimalSum whose result is the DECIMAL(65,30)
+// SUM as MySQL computes it. The zero value is an empty sum ready to use.
+type DecimalAccumulator struct {
+	sum DecimalSum
+}
+
+// Add adds d to the sum, it doesn't allocate while the sum fits in a MyDecimal.
+func (a *DecimalAccumulator) Add(d *MyDecimal) error {
+	return a.sum.Add(d)
+}
+
+// Result returns the sum at the largest scale of the added decimals, at most mysql.MaxDecimalScale.
+// It returns ErrOverflow along with the max or min value if the sum exceeds the DECIMAL(65,30) limits.
+func (a *DecimalAccumulator) Result() (*MyDecimal, error) {
+	return a.sum.Result(mysql.MaxDecimalWidth, a.sum.Frac())
+}
+
 // DecimalSub subs one decimal from another, sets the result to 'to'.
 func DecimalSub(from1, from2, to *MyDecimal) error {
 	from1, from2, to = validateArgs(from1, from2, to)
@@ -2078,6 +2425,23 @@ func DecimalMod(from1, from2, to *MyDecimal) error {
 	return doDivMod(from1, from2, nil, to, 0)
 }
 
+// DivMod divides d by from, setting quotient like DecimalDiv with DivFracIncr and remainder like
+// DecimalMod, so the sign of the remainder follows d as in MySQL. It returns ErrDivByZero and
+// leaves quotient and remainder untouched if from is zero, otherwise the first error of the
+// division and the modulus, e.g. ErrTruncated. quotient and remainder may alias d or from.
+func (d *MyDecimal) DivMod(from *MyDecimal, quotient, remainder *MyDecimal) error {
+	var q, r MyDecimal
+	err := DecimalDiv(d, from, &q, DivFracIncr)
+	if err == ErrDivByZero {
+		return err
+	}
+	if err1 := DecimalMod(d, from, &r); err == nil {
+		err = err1
+	}
+	*quotient, *remainder = q, r
+	return err
+}
+
 func doDivMod(from1, from2, to, mod *MyDecimal, fracIncr int) error {
 	var (
 		frac1 = digitsToWords(int(from1.digitsFrac)) * digitsPerWord
@@ -2353,6 +2717,84 @@ func NewDecFromUint(i uint64) *MyDecimal {
 	return new(MyDecimal).FromUint(i)
 }
 
+// FromBigInt creates a MyDecimal of the value b * 10^-scale, e.g. a 128-bit integer of another
+// system with an implied scale. ErrOverflow is returned if the value has more than
+// mysql.MaxDecimalWidth digits, and ErrBadNumber if scale is out of [0, mysql.MaxDecimalScale].
+func FromBigInt(b *big.Int, scale int) (*MyDecimal, error) {
+	if scale < 0 || scale > mysql.MaxDecimalScale {
+		return nil, ErrBadNumber
+	}
+	digits := b.Text(10)
+	sign := ""
+	if b.Sign() < 0 {
+		sign, digits = "-", digits[1:]
+	}
+	if len(digits) > mysql.MaxDecimalWidth {
+		return nil, ErrOverflow
+	}
+	if len(digits) <= scale {
+		digits = strings.Repeat("0", scale-len(digits)+1) + digits
+	}
+	intLen := len(digits) - scale
+	dec := new(MyDecimal)
+	if err := dec.FromString([]byte(sign + digits[:intLen] + "." + digits[intLen:])); err != nil {
+		return nil, err
+	}
+	return dec, nil
+}
+
+// Sqrt returns the square root of d. Like the quotient of DecimalDiv, the result has DivFracIncr
+// more fraction digits than d, at most mysql.MaxDecimalScale, and its last digit is correctly
+// rounded half up. ErrBadNumber is returned if d is negative.
+func (d *MyDecimal) Sqrt() (*MyDecimal, error) {
+	if d.IsNegative() {
+		return nil, ErrBadNumber
+	}
+	frac := myMin(int(d.resultFrac)+DivFracIncr, mysql.MaxDecimalScale)
+	// r is sqrt(d) with one more digit than frac, rounded down, which decides the rounding as
+	// the square root of a non-square is never a tie.
+	r, _ := sqrtNewton(d.toScaledBigInt(2 * (frac + 1)))
+	r.Add(r, big.NewInt(5))
+	r.Quo(r, big.NewInt(10))
+	return FromBigInt(r, frac)
+}
+
+// toScaledBigInt returns d * 10^scale rounded down to an integer.
+func (d *MyDecimal) toScaledBigInt(scale int) *big.Int {
+	str := string(d.ToString())
+	intPart, fracPart := str, ""
+	if point := strings.IndexByte(str, '.'); point >= 0 {
+		intPart, fracPart = str[:point], str[point+1:]
+	}
+	if len(fracPart) > scale {
+		fracPart = fracPart[:scale]
+	}
+	n, _ := new(big.Int).SetString(intPart+fracPart, 10)
+	return n.Mul(n, new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(scale-len(fracPart))), nil))
+}
+
+// sqrtNewton returns the square root of the non-negative n rounded down, and the number of
+// Newton's iterations it takes. The float64 square root is a starting point above the result
+// with about 15 correct digits, and each iteration about doubles them.
+func sqrtNewton(n *big.Int) (x *big.Int, iterations int) {
+	if n.Sign() == 0 {
+		return new(big.Int), 0
+	}
+	f, _ := new(big.Float).SetInt(n).Float64()
+	x, _ = big.NewFloat(math.Sqrt(f) * (1 + 1e-14)).Int(nil)
+	x.Add(x, big.NewInt(1))
+	for {
+		// Starting above the result, x decreases until it is the result.
+		y := new(big.Int).Quo(n, x)
+		y.Add(y, x).Rsh(y, 1)
+		iterations++
+		if y.Cmp(x) >= 0 {
+			return x, iterations
+		}
+		x = y
+	}
+}
+
 // NewDecFromFloatForTest creates a MyDecimal from float, as it returns no error, it should only be used in test.
 func NewDecFromFloatForTest(f float64) *MyDecimal {
 	dec := new(MyDecimal)
@@ -19,6 +19,8 @@ import (
 	"math/rand"
 	"strconv"
 	"testing"
+
+	"github.com/pingcap/tidb/parser/mysql"
 )
 
 const (
@@ -174,3 +176,92 @@ func BenchmarkMyDecimalToHashKey(b *testing.B) {
 		}
 	}
 }
+
+func BenchmarkMyDecimalCompareConstant(b *testing.B) {
+	cases := benchmarkMyDecimalToBinOrHashCases()
+	decs := make([]*MyDecimal, 0, len(cases))
+	for _, ca := range cases {
+		var dec MyDecimal
+		if err := dec.FromString([]byte(ca)); err != nil {
+			b.Fatal(err)
+		}
+		decs = append(decs, &dec)
+	}
+	constant := NewDecFromStringForTest("0000123456789.987654321000")
+
+	b.Run("Compare", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			for _, dec := range decs {
+				constant.Compare(dec)
+			}
+		}
+	})
+	b.Run("PreparedDecimal", func(b *testing.B) {
+		prepared := NewPreparedDecimal(constant)
+		for i := 0; i < b.N; i++ {
+			for _, dec := range decs {
+				prepared.CompareTo(dec)
+			}
+		}
+	})
+}
+
+func BenchmarkVecCompareDD(b *testing.B) {
+	cases := benchmarkMyDecimalToBinOrHashCases()
+	lhs := make([]MyDecimal, 0, len(cases)*len(cases))
+	rhs := make([]MyDecimal, 0, len(cases)*len(cases))
+	for _, x := range cases {
+		for _, y := range cases {
+			lhs = append(lhs, *NewDecFromStringForTest(x))
+			rhs = append(rhs, *NewDecFromStringForTest(y))
+		}
+	}
+	res := make([]int64, len(lhs))
+
+	b.Run("Compare", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			for j := range lhs {
+				res[j] = int64(lhs[j].Compare(&rhs[j]))
+			}
+		}
+	})
+	b.Run("VecCompareDD", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			VecCompareDD(lhs, rhs, res)
+		}
+	})
+}
+
+func BenchmarkDecimalSum(b *testing.B) {
+	decs := make([]*MyDecimal, 0, 1024)
+	for i := 0; i < cap(decs); i++ {
+		decs = append(decs, NewDecFromFloatForTest(float64(i-512)+float64(i*7919%1000000)/1e6))
+	}
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		var sum DecimalSum
+		for _, dec := range decs {
+			_ = sum.Add(dec)
+		}
+	}
+}
+
+// BenchmarkSqrt reports the Newton's iterations of MyDecimal.Sqrt, which stay within a few for
+// typical magnitudes.
+func BenchmarkSqrt(b *testing.B) {
+	for _, str := range []string{"2", "123456.789", "0.000001234", "98765432109876543210.0123456789"} {
+		dec := NewDecFromStringForTest(str)
+		b.Run(str, func(b *testing.B) {
+			frac := myMin(int(dec.resultFrac)+DivFracIncr, mysql.MaxDecimalScale)
+			_, iterations := sqrtNewton(dec.toScaledBigInt(2 * (frac + 1)))
+			b.ReportMetric(float64(iterations), "iterations")
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if _, err := dec.Sqrt(); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
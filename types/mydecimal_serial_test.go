@@ -169,6 +169,11 @@ func TestFromStringMyDecimal(t *testing.T) {
 		{"1234500009876.5", "1234500009876.5", nil},
 		{"123E5", "12300000", nil},
 		{"123E-2", "1.23", nil},
+		{"1.5E3", "1500", nil},
+		{"-1.5e+3", "-1500", nil},
+		{"1.5E-3", "0.0015", nil},
+		{"-1.5E-3", "-0.0015", nil},
+		{"1.5E100", "999999999999999999999999999999999999999999999999999999999999999999999999999999999", ErrOverflow},
 		{"1e1073741823", "999999999999999999999999999999999999999999999999999999999999999999999999999999999", ErrOverflow},
 		{"-1e1073741823", "-999999999999999999999999999999999999999999999999999999999999999999999999999999999", ErrOverflow},
 		{"1e18446744073709551620", "0", ErrBadNumber},
@@ -15,10 +15,15 @@
 package types
 
 import (
+	"fmt"
+	"math"
+	"math/big"
+	"math/rand"
 	"strconv"
 	"strings"
 	"testing"
 
+	"github.com/pingcap/tidb/parser/mysql"
 	"github.com/stretchr/testify/require"
 )
 
@@ -59,6 +64,46 @@ func TestFromUint(t *testing.T) {
 	}
 }
 
+func TestFromBigInt(t *testing.T) {
+	t.Parallel()
+	maxInt128 := new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 127), big.NewInt(1))
+	minInt128 := new(big.Int).Neg(new(big.Int).Lsh(big.NewInt(1), 127))
+	tests := []struct {
+		input  *big.Int
+		scale  int
+		output string
+	}{
+		{maxInt128, 0, "170141183460469231731687303715884105727"},
+		{maxInt128, 10, "17014118346046923173168730371.5884105727"},
+		{maxInt128, 30, "170141183.460469231731687303715884105727"},
+		{minInt128, 2, "-1701411834604692317316873037158841057.28"},
+		{big.NewInt(-5), 3, "-0.005"},
+		{big.NewInt(12345), 5, "0.12345"},
+		{big.NewInt(0), 0, "0"},
+	}
+	for _, tt := range tests {
+		dec, err := FromBigInt(tt.input, tt.scale)
+		require.NoError(t, err)
+		require.Equal(t, tt.output, dec.String())
+	}
+
+	// 65 digits is the max precision of a decimal.
+	maxDigits, ok := new(big.Int).SetString(strings.Repeat("9", mysql.MaxDecimalWidth), 10)
+	require.True(t, ok)
+	dec, err := FromBigInt(maxDigits, 30)
+	require.NoError(t, err)
+	require.Equal(t, strings.Repeat("9", 35)+"."+strings.Repeat("9", 30), dec.String())
+	_, err = FromBigInt(new(big.Int).Add(maxDigits, big.NewInt(1)), 30)
+	require.Equal(t, ErrOverflow, err)
+	_, err = FromBigInt(new(big.Int).Neg(new(big.Int).Mul(maxDigits, big.NewInt(10))), 0)
+	require.Equal(t, ErrOverflow, err)
+
+	_, err = FromBigInt(big.NewInt(1), -1)
+	require.Equal(t, ErrBadNumber, err)
+	_, err = FromBigInt(big.NewInt(1), mysql.MaxDecimalScale+1)
+	require.Equal(t, ErrBadNumber, err)
+}
+
 func TestToInt(t *testing.T) {
 	t.Parallel()
 	tests := []struct {
@@ -186,6 +231,22 @@ func TestToFloat(t *testing.T) {
 		require.NoError(t, err)
 		require.Equal(t, std, f)
 	}
+
+	// A decimal holds at most 81 digits, so a 400-digit number overflows while parsing and is
+	// cut to 81 nines, which is still a finite float64.
+	var dec MyDecimal
+	err := dec.FromString([]byte(strings.Repeat("9", 400)))
+	require.Equal(t, ErrOverflow, err)
+	f, err := dec.ToFloat64()
+	require.NoError(t, err)
+	require.False(t, math.IsInf(f, 0))
+	require.InEpsilon(t, 1e81, f, 1e-15)
+	err = dec.FromString([]byte("-1e400"))
+	require.Equal(t, ErrOverflow, err)
+	f, err = dec.ToFloat64()
+	require.NoError(t, err)
+	require.False(t, math.IsInf(f, 0))
+	require.InEpsilon(t, -1e81, f, 1e-15)
 }
 
 func TestToHashKey(t *testing.T) {
@@ -337,6 +398,70 @@ func TestRoundWithHalfEven(t *testing.T) {
 	}
 }
 
+func TestRoundWithHalfToEven(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		input  string
+		scale  int
+		output string
+	}{
+		{"2.5", 0, "2"},
+		{"3.5", 0, "4"},
+		{"-2.5", 0, "-2"},
+		{"-3.5", 0, "-4"},
+		{"0.5", 0, "0"},
+		{"2.51", 0, "3"},
+		{"2.500000000000000001", 0, "3"},
+		{"2.25", 1, "2.2"},
+		{"2.35", 1, "2.4"},
+		{"2.24", 1, "2.2"},
+		{"2.26", 1, "2.3"},
+		{"1234567892.5", 0, "1234567892"},
+		{"1234567891.5", 0, "1234567892"},
+		{"1.0000000005", 9, "1.000000000"},
+		{"1.0000000015", 9, "1.000000002"},
+		{"25", -1, "20"},
+		{"35", -1, "40"},
+		{"150", -2, "200"},
+		{"250.0001", -2, "300"},
+		{"9.5", 0, "10"},
+		{"99.95", 1, "100.0"},
+		{"12.34", 5, "12.34000"},
+	}
+
+	for _, ca := range tests {
+		var dec MyDecimal
+		err := dec.FromString([]byte(ca.input))
+		require.NoError(t, err)
+		var rounded MyDecimal
+		err = dec.Round(&rounded, ca.scale, ModeHalfToEven)
+		require.NoError(t, err)
+		require.Equal(t, ca.output, string(rounded.ToString()), "%s to %d", ca.input, ca.scale)
+	}
+}
+
+func TestRoundModesOnTie(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		mode     RoundMode
+		positive string
+		negative string
+	}{
+		{ModeHalfUp, "3", "-3"},
+		{ModeHalfEven, "3", "-3"},
+		{ModeHalfToEven, "2", "-2"},
+		{ModeTruncate, "2", "-2"},
+	}
+	for _, ca := range tests {
+		for input, output := range map[string]string{"2.5": ca.positive, "-2.5": ca.negative} {
+			var dec, rounded MyDecimal
+			require.NoError(t, dec.FromString([]byte(input)))
+			require.NoError(t, dec.Round(&rounded, 0, ca.mode))
+			require.Equal(t, output, rounded.String(), "%s with mode %d", input, ca.mode)
+		}
+	}
+}
+
 func TestRoundWithTruncate(t *testing.T) {
 	t.Parallel()
 	tests := []struct {
@@ -430,6 +555,433 @@ func TestToString(t *testing.T) {
 	}
 }
 
+func TestStringFixed(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		input    string
+		decimals int
+		output   string
+	}{
+		{"123.456", 2, "123.46"},
+		{"123.454", 2, "123.45"},
+		{"123.455", 2, "123.46"},
+		{"-123.455", 2, "-123.46"},
+		{"1.005", 2, "1.01"},
+		{"0.5", 0, "1"},
+		{"-0.5", 0, "-1"},
+		{"2.5", 0, "3"},
+		{"0.4", 0, "0"},
+		{"-0.4", 0, "0"},
+		{"-0.04", 1, "0.0"},
+		{"-0.05", 1, "-0.1"},
+		{"9.995", 2, "10.00"},
+		{"-9.995", 2, "-10.00"},
+		{"999999999.9999999995", 9, "1000000000.000000000"},
+		{"0.0000000005", 9, "0.000000001"},
+		{"0", 2, "0.00"},
+		{"1", 3, "1.000"},
+		{"-1.2", 5, "-1.20000"},
+		{"0.000000001", 12, "0.000000001000"},
+		{"12", 0, "12"},
+		{"123.456", -1, "123"},
+	}
+	for _, ca := range tests {
+		var dec MyDecimal
+		err := dec.FromString([]byte(ca.input))
+		require.NoError(t, err)
+		require.Equal(t, ca.output, dec.StringFixed(ca.decimals), "%s with %d decimals", ca.input, ca.decimals)
+	}
+
+	// Values without ties format the same as strconv.FormatFloat.
+	for _, f := range []float64{0, 1.2, -3.7, 123456.789, -0.0312, 0.0004} {
+		var dec MyDecimal
+		require.NoError(t, dec.FromFloat64(f))
+		for decimals := 0; decimals <= 6; decimals++ {
+			expected := strconv.FormatFloat(f, 'f', decimals, 64)
+			if expected == "-0" || expected == "-0.0" || expected == "-0.00" {
+				expected = expected[1:]
+			}
+			require.Equal(t, expected, dec.StringFixed(decimals), "%v with %d decimals", f, decimals)
+		}
+	}
+}
+
+func TestFromBytes(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		input string
+		n     int
+	}{
+		{"12345", 5},
+		{"  -12.3400", 10},
+		{"+0.5", 4},
+		{"1.5E3", 5},
+		{"1.5e-3abc", 6},
+		{"12abc", 2},
+		{"1e", 1},
+		{"1e+", 1},
+		{"1e 5", 4},
+		{"-.5e2x", 5},
+		{"", 0},
+		{"  ", 0},
+		{"abc", 0},
+		{".", 0},
+	}
+	for _, tt := range tests {
+		var fromBytes, fromString MyDecimal
+		n, err := fromBytes.FromBytes([]byte(tt.input))
+		err1 := fromString.FromString([]byte(tt.input))
+		require.Equal(t, tt.n, n, tt.input)
+		require.Equal(t, err1, err, tt.input)
+		require.Equal(t, fromString, fromBytes, tt.input)
+	}
+}
+
+func TestRoundToSignificant(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		input  string
+		n      int
+		output string
+	}{
+		{"123.456", 2, "120"},
+		{"123.456", 4, "123.5"},
+		{"123.456", 10, "123.4560000"},
+		{"-98765", 3, "-98800"},
+		{"99.5", 2, "100"},
+		{"1.05", 2, "1.1"},
+		{"0.001234", 2, "0.0012"},
+		{"0.001235", 3, "0.00124"},
+		{"-0.5", 1, "-0.5"},
+		{"1234567890.123456789", 12, "1234567890.12"},
+		{"0", 3, "0"},
+	}
+	for _, tt := range tests {
+		var dec, to MyDecimal
+		require.NoError(t, dec.FromString([]byte(tt.input)))
+		require.NoError(t, dec.RoundToSignificant(tt.n, &to))
+		require.Equal(t, tt.output, to.String(), "%s %d", tt.input, tt.n)
+	}
+
+	var to MyDecimal
+	require.Equal(t, ErrBadNumber, NewDecFromInt(1).RoundToSignificant(0, &to))
+}
+
+func TestPreparedDecimal(t *testing.T) {
+	t.Parallel()
+	r := rand.New(rand.NewSource(1))
+	randomDecimal := func() *MyDecimal {
+		var s string
+		switch r.Intn(4) {
+		case 0:
+			s = strconv.FormatInt(r.Int63n(2000)-1000, 10)
+		case 1:
+			s = fmt.Sprintf("%d.%d", r.Int63n(2000)-1000, r.Int63n(1000))
+		case 2:
+			s = fmt.Sprintf("%d.%018d", r.Int63()-r.Int63(), r.Int63n(1e18))
+		default:
+			s = fmt.Sprintf("0.%09d%09d", r.Int63n(1e9), r.Int63n(1e9))
+		}
+		return NewDecFromStringForTest(s)
+	}
+	for i := 0; i < 100; i++ {
+		d := randomDecimal()
+		prepared := NewPreparedDecimal(d)
+		for j := 0; j < 100; j++ {
+			other := randomDecimal()
+			require.Equal(t, d.Compare(other), prepared.CompareTo(other), "%s %s", d, other)
+		}
+		require.Equal(t, 0, prepared.CompareTo(d), d.String())
+	}
+
+	tests := []struct {
+		a, b string
+	}{
+		{"0", "-0.000"},
+		{"1.10", "1.1"},
+		{"000123.450", "123.45"},
+		{"999999999", "1000000000"},
+		{"0.000000001", "0.0000000009"},
+		{"-1", "-1.000000001"},
+		{"12345678901234567890.5", "12345678901234567890.50000000001"},
+	}
+	for _, tt := range tests {
+		a, b := NewDecFromStringForTest(tt.a), NewDecFromStringForTest(tt.b)
+		require.Equal(t, a.Compare(b), NewPreparedDecimal(a).CompareTo(b), "%s %s", tt.a, tt.b)
+		require.Equal(t, b.Compare(a), NewPreparedDecimal(b).CompareTo(a), "%s %s", tt.b, tt.a)
+	}
+
+	// The prepared decimal doesn't change along with the source.
+	d := NewDecFromStringForTest("1.5")
+	prepared := NewPreparedDecimal(d)
+	require.NoError(t, d.FromString([]byte("2")))
+	require.Equal(t, -1, prepared.CompareTo(d))
+}
+
+func TestLeadingZeros(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		input  string
+		expect int
+	}{
+		{"0", 1},
+		{"0.05", 1},
+		{".05", 0},
+		{"-0.000001", 1},
+		{"000.5", 3},
+		{"1", 0},
+		{"1.05", 0},
+		{"100", 0},
+		{"000100", 3},
+		{"-100.001", 0},
+		{"123456789012345678901234567890.5", 0},
+		{"99999999999999999999999999999999999999999999999999999999999999999", 0},
+	}
+	for _, tt := range tests {
+		var dec MyDecimal
+		require.NoError(t, dec.FromString([]byte(tt.input)))
+		require.Equal(t, tt.expect, dec.LeadingZeros(), tt.input)
+	}
+
+	// The integer part of a DECIMAL(10,2) is 8 digits wide, but FromBin drops a word of zeros.
+	for input, expect := range map[string]int{"5.00": 7, "0.05": 0, "12345678.99": 0, "-1234.5": 4} {
+		bin, err := NewDecFromStringForTest(input).ToBin(10, 2)
+		require.NoError(t, err)
+		var dec MyDecimal
+		_, err = dec.FromBin(bin, 10, 2)
+		require.NoError(t, err)
+		require.Equal(t, expect, dec.LeadingZeros(), input)
+	}
+
+	// FromUint holds whole words of 9 digits.
+	require.Equal(t, 6, NewDecFromUint(100).LeadingZeros())
+	require.Equal(t, 8, NewDecFromUint(1000000000).LeadingZeros())
+}
+
+func TestCompareExact(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		a, b   string
+		result int
+	}{
+		{"1.5", "1.50", 0},
+		{"1.5", "1.500000000000000001", -1},
+		{"-0.1", "-0.10", 0},
+		{"123", "123.000", 0},
+		{"123.45", "123", 1},
+	}
+	for _, tt := range tests {
+		a, b := NewDecFromStringForTest(tt.a), NewDecFromStringForTest(tt.b)
+		result, exact := a.CompareExact(b)
+		require.Equal(t, tt.result, result, "%s %s", tt.a, tt.b)
+		require.True(t, exact, "%s %s", tt.a, tt.b)
+	}
+
+	// The quotient holds digits beyond its result fraction, it's equal to 0.3333 only when rounded.
+	var quotient MyDecimal
+	require.NoError(t, DecimalDiv(NewDecFromInt(1), NewDecFromInt(3), &quotient, DivFracIncr))
+	rounded := NewDecFromStringForTest("0.3333")
+	require.Equal(t, rounded.String(), quotient.String())
+	result, exact := quotient.CompareExact(rounded)
+	require.Equal(t, 1, result)
+	require.False(t, exact)
+	result, exact = rounded.CompareExact(&quotient)
+	require.Equal(t, -1, result)
+	require.False(t, exact)
+
+	// The quotient of 1/4 has no hidden digits.
+	require.NoError(t, DecimalDiv(NewDecFromInt(1), NewDecFromInt(4), &quotient, DivFracIncr))
+	result, exact = quotient.CompareExact(NewDecFromStringForTest("0.25"))
+	require.Equal(t, 0, result)
+	require.True(t, exact)
+}
+
+func TestClone(t *testing.T) {
+	t.Parallel()
+	src := NewDecFromStringForTest("-123456789012345678.9")
+	clone := src.Clone()
+	require.Equal(t, 0, src.Compare(clone))
+
+	clone.NegInPlace()
+	require.Equal(t, "123456789012345678.9", clone.String())
+	require.Equal(t, "-123456789012345678.9", src.String())
+
+	var sum MyDecimal
+	require.NoError(t, DecimalAdd(clone, NewDecFromInt(1), &sum))
+	*clone = sum
+	require.Equal(t, "123456789012345679.9", clone.String())
+	require.Equal(t, "-123456789012345678.9", src.String())
+
+	zero := NewDecFromInt(0)
+	zero.NegInPlace()
+	require.False(t, zero.IsNegative())
+}
+
+func TestDecimalSum(t *testing.T) {
+	t.Parallel()
+	r := rand.New(rand.NewSource(1))
+	var sum DecimalSum
+	exact := new(big.Rat)
+	for i := 0; i < 10000; i++ {
+		s := fmt.Sprintf("%d.%010d", r.Int63n(1000)-500, r.Int63n(1e10))
+		var dec MyDecimal
+		require.NoError(t, dec.FromString([]byte(s)))
+		require.NoError(t, sum.Add(&dec))
+		v, ok := new(big.Rat).SetString(s)
+		require.True(t, ok)
+		exact.Add(exact, v)
+	}
+	res, err := sum.Result(65, 10)
+	require.NoError(t, err)
+	require.Equal(t, exact.FloatString(10), res.String())
+	res, err = sum.Result(65, 4)
+	require.NoError(t, err)
+	require.Equal(t, exact.FloatString(4), res.String())
+
+	var small DecimalSum
+	for i := 0; i < 3; i++ {
+		require.NoError(t, small.Add(NewDecFromStringForTest("99999.99")))
+	}
+	res, err = small.Result(8, 2)
+	require.NoError(t, err)
+	require.Equal(t, "299999.97", res.String())
+	res, err = small.Result(7, 2)
+	require.Equal(t, ErrOverflow, err)
+	require.Equal(t, "99999.99", res.String())
+
+	// Carries across the 9-digit word boundary.
+	carryTests := []struct {
+		inputs []string
+		expect string
+	}{
+		{[]string{"999999999", "1"}, "1000000000"},
+		{[]string{"999999999999999999", "1"}, "1000000000000000000"},
+		{[]string{"0.999999999", "0.000000001"}, "1.000000000"},
+		{[]string{"-999999999", "-1"}, "-1000000000"},
+		{[]string{"1000000000", "-1"}, "999999999"},
+	}
+	for _, tt := range carryTests {
+		var carry DecimalSum
+		for _, in := range tt.inputs {
+			require.NoError(t, carry.Add(NewDecFromStringForTest(in)))
+		}
+		res, err = carry.Result(30, 9)
+		require.NoError(t, err)
+		expect := NewDecFromStringForTest(tt.expect)
+		require.Equal(t, 0, res.Compare(expect), "%v: got %s", tt.inputs, res.String())
+	}
+
+	var empty DecimalSum
+	res, err = empty.Result(10, 2)
+	require.NoError(t, err)
+	require.Equal(t, "0.00", res.String())
+
+	// A sum which doesn't fit in the 81 digits of a MyDecimal is widened and stays exact.
+	huge := "1" + strings.Repeat("0", 60)
+	tiny := "0." + strings.Repeat("0", 29) + "1"
+	var wide DecimalSum
+	require.NoError(t, wide.Add(NewDecFromStringForTest(huge)))
+	require.NoError(t, wide.Add(NewDecFromStringForTest(tiny)))
+	require.NotNil(t, wide.wide)
+	res, err = wide.Result(65, 30)
+	require.Equal(t, ErrOverflow, err)
+	require.Equal(t, NewMaxOrMinDec(false, 65, 30).String(), res.String())
+	require.NoError(t, wide.Add(NewDecFromStringForTest("-"+huge)))
+	require.NoError(t, wide.Add(NewDecFromStringForTest("-1.5")))
+	res, err = wide.Result(65, 30)
+	require.NoError(t, err)
+	require.Equal(t, "-1.499999999999999999999999999999", res.String())
+	res, err = wide.Result(10, 0)
+	require.NoError(t, err)
+	require.Equal(t, "-1", res.String())
+	res, err = wide.Result(10, 1)
+	require.NoError(t, err)
+	require.Equal(t, "-1.5", res.String())
+}
+
+func TestDecimalSumFrac(t *testing.T) {
+	t.Parallel()
+	// The scale is the largest one of the added decimals.
+	var scale DecimalSum
+	for _, s := range []string{"1", "0.5", "-2.125", "3.10"} {
+		require.NoError(t, scale.Add(NewDecFromStringForTest(s)))
+	}
+	require.Equal(t, 3, scale.Frac())
+	res, err := scale.Result(mysql.MaxDecimalWidth, scale.Frac())
+	require.NoError(t, err)
+	require.Equal(t, "2.475", res.String())
+
+	var empty DecimalSum
+	require.Equal(t, 0, empty.Frac())
+	res, err = empty.Result(mysql.MaxDecimalWidth, empty.Frac())
+	require.NoError(t, err)
+	require.Equal(t, "0", res.String())
+
+	// At most 35 integer digits are allowed at the largest scale.
+	max35 := strings.Repeat("9", 35)
+	var overflow DecimalSum
+	require.NoError(t, overflow.Add(NewDecFromStringForTest(max35+"."+strings.Repeat("5", 30))))
+	require.Equal(t, mysql.MaxDecimalScale, overflow.Frac())
+	_, err = overflow.Result(mysql.MaxDecimalWidth, overflow.Frac())
+	require.NoError(t, err)
+	require.NoError(t, overflow.Add(NewDecFromStringForTest("0.5")))
+	res, err = overflow.Result(mysql.MaxDecimalWidth, overflow.Frac())
+	require.Equal(t, ErrOverflow, err)
+	require.Equal(t, NewMaxOrMinDec(false, mysql.MaxDecimalWidth, mysql.MaxDecimalScale).String(), res.String())
+	// The sum is exact, so it's back in range after subtracting.
+	require.NoError(t, overflow.Add(NewDecFromStringForTest("-1")))
+	res, err = overflow.Result(mysql.MaxDecimalWidth, overflow.Frac())
+	require.NoError(t, err)
+	require.Equal(t, max35+".0"+strings.Repeat("5", 29), res.String())
+
+	var negOverflow DecimalSum
+	require.NoError(t, negOverflow.Add(NewDecFromStringForTest("-"+max35+"."+strings.Repeat("0", 30))))
+	require.NoError(t, negOverflow.Add(NewDecFromStringForTest("-1")))
+	res, err = negOverflow.Result(mysql.MaxDecimalWidth, negOverflow.Frac())
+	require.Equal(t, ErrOverflow, err)
+	require.True(t, res.IsNegative())
+
+	// Adding doesn't allocate while the sum fits in a MyDecimal.
+	var noAlloc DecimalSum
+	one := NewDecFromStringForTest("1.5")
+	allocs := testing.AllocsPerRun(100, func() {
+		_ = noAlloc.Add(one)
+	})
+	require.Zero(t, allocs)
+}
+
+func TestDecimalAccumulator(t *testing.T) {
+	t.Parallel()
+	var acc DecimalAccumulator
+	res, err := acc.Result()
+	require.NoError(t, err)
+	require.Equal(t, "0", res.String())
+	for _, s := range []string{"1", "0.5", "-2.125", "3.10"} {
+		require.NoError(t, acc.Add(NewDecFromStringForTest(s)))
+	}
+	res, err = acc.Result()
+	require.NoError(t, err)
+	require.Equal(t, "2.475", res.String())
+
+	max35 := strings.Repeat("9", 35)
+	var overflow DecimalAccumulator
+	require.NoError(t, overflow.Add(NewDecFromStringForTest(max35+"."+strings.Repeat("9", 30))))
+	require.NoError(t, overflow.Add(NewDecFromStringForTest("1")))
+	res, err = overflow.Result()
+	require.Equal(t, ErrOverflow, err)
+	require.Equal(t, NewMaxOrMinDec(false, mysql.MaxDecimalWidth, mysql.MaxDecimalScale).String(), res.String())
+	require.NoError(t, overflow.Add(NewDecFromStringForTest("-1")))
+	res, err = overflow.Result()
+	require.NoError(t, err)
+	require.Equal(t, max35+"."+strings.Repeat("9", 30), res.String())
+
+	one := NewDecFromStringForTest("1.5")
+	allocs := testing.AllocsPerRun(100, func() {
+		_ = acc.Add(one)
+	})
+	require.Zero(t, allocs)
+}
+
 func TestToBinFromBin(t *testing.T) {
 	t.Parallel()
 	type tcase struct {
@@ -582,6 +1134,34 @@ func TestNegMyDecimal(t *testing.T) {
 	}
 }
 
+func TestSetNegative(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		a   string
+		neg bool
+		res string
+	}{
+		{"1.5", true, "-1.5"},
+		{"1.5", false, "1.5"},
+		{"-1.5", false, "1.5"},
+		{"-1.5", true, "-1.5"},
+		{"0.00", true, "0.00"},
+		{"0", false, "0"},
+		{"-0.000000000000000000000000000001", false, "0.000000000000000000000000000001"},
+	}
+	for _, tt := range tests {
+		a := NewDecFromStringForTest(tt.a)
+		a.SetNegative(tt.neg)
+		require.Equal(t, tt.res, a.String(), tt.a)
+		require.Equal(t, tt.neg && !a.IsZero(), a.IsNegative(), tt.a)
+	}
+
+	zero := new(MyDecimal)
+	zero.SetNegative(true)
+	require.False(t, zero.IsNegative())
+	require.Equal(t, 0, zero.Compare(NewDecFromInt(0)))
+}
+
 func TestAddMyDecimal(t *testing.T) {
 	t.Parallel()
 	type testCase struct {
@@ -800,6 +1380,129 @@ func TestDivModMyDecimal(t *testing.T) {
 	}
 }
 
+func TestDivMod(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		a         string
+		b         string
+		quotient  string
+		remainder string
+	}{
+		{"234", "10", "23.4000", "4"},
+		{"-234", "10", "-23.4000", "-4"},
+		{"234", "-10", "-23.4000", "4"},
+		{"-234", "-10", "23.4000", "-4"},
+		{"234.567", "10.555", "22.2233065", "2.357"},
+		{"-234.567", "10.555", "-22.2233108", "-2.357"},
+		{"51", "0.003430", "14868.8047", "0.002760"},
+		{"2", "3", "0.6667", "2"},
+		{"0.000", "0.1", "0.0000000", "0.000"},
+	}
+	for _, tt := range tests {
+		var a, b, quotient, remainder MyDecimal
+		require.NoError(t, a.FromString([]byte(tt.a)))
+		require.NoError(t, b.FromString([]byte(tt.b)))
+		require.NoError(t, a.DivMod(&b, &quotient, &remainder))
+		require.Equal(t, tt.quotient, quotient.String(), "%s / %s", tt.a, tt.b)
+		require.Equal(t, tt.remainder, remainder.String(), "%s %% %s", tt.a, tt.b)
+
+		// The same as dividing and taking the modulus separately.
+		var div, mod MyDecimal
+		require.NoError(t, DecimalDiv(&a, &b, &div, DivFracIncr))
+		require.NoError(t, DecimalMod(&a, &b, &mod))
+		require.Equal(t, 0, div.Compare(&quotient))
+		require.Equal(t, div.String(), quotient.String())
+		require.Equal(t, 0, mod.Compare(&remainder))
+		require.Equal(t, mod.String(), remainder.String())
+
+		// The results may be written to the operands.
+		x, y := a, b
+		require.NoError(t, x.DivMod(&y, &x, &y))
+		require.Equal(t, tt.quotient, x.String())
+		require.Equal(t, tt.remainder, y.String())
+	}
+
+	var a, zero, quotient, remainder MyDecimal
+	a.FromInt(1)
+	quotient.FromInt(7)
+	remainder.FromInt(8)
+	require.Equal(t, ErrDivByZero, a.DivMod(&zero, &quotient, &remainder))
+	require.Equal(t, "7", quotient.String())
+	require.Equal(t, "8", remainder.String())
+}
+
+func TestSqrt(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		input  string
+		output string
+	}{
+		{"4", "2.0000"},
+		{"2", "1.4142"},
+		{"2.00", "1.414214"},
+		{"0.5", "0.70711"},
+		{"0", "0.0000"},
+		{"0.01", "0.100000"},
+		{"1.0000", "1.00000000"},
+		{"123.456", "11.1110756"},
+		{"10000000000", "100000.0000"},
+		{"0.000000000000000000000000000001", "0.000000000000001000000000000000"},
+		{"99999999999999999999999999999999999999999999999999999999999999999", "316227766016837933199889354443271.8534"},
+	}
+	for _, tt := range tests {
+		var dec MyDecimal
+		require.NoError(t, dec.FromString([]byte(tt.input)))
+		ret, err := dec.Sqrt()
+		require.NoError(t, err)
+		require.Equal(t, tt.output, ret.String(), tt.input)
+	}
+
+	var neg MyDecimal
+	require.NoError(t, neg.FromString([]byte("-0.01")))
+	_, err := neg.Sqrt()
+	require.Equal(t, ErrBadNumber, err)
+
+	// The last digit is correctly rounded, compared with a much more precise big.Float.
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 1000; i++ {
+		str := strconv.FormatInt(r.Int63n(1<<uint(r.Intn(62)+1)), 10)
+		for j, n := 0, r.Intn(25); j < n; j++ {
+			if j == 0 {
+				str += "."
+			}
+			str += strconv.Itoa(r.Intn(10))
+		}
+		var dec MyDecimal
+		require.NoError(t, dec.FromString([]byte(str)))
+		ret, err := dec.Sqrt()
+		require.NoError(t, err)
+
+		x, _, err := big.ParseFloat(str, 10, 1000, big.ToNearestEven)
+		require.NoError(t, err)
+		ref := x.Sqrt(x).Text('f', int(ret.GetDigitsFrac())+20)
+		var expected MyDecimal
+		require.NoError(t, expected.FromString([]byte(ref)))
+		require.NoError(t, expected.Round(&expected, int(ret.GetDigitsFrac()), ModeHalfUp))
+		require.Equal(t, 0, expected.Compare(ret), "sqrt(%s) = %s, expected %s", str, ret.String(), expected.String())
+	}
+}
+
+func TestSqrtNewtonIterations(t *testing.T) {
+	t.Parallel()
+	// Starting from the float64 square root, a few iterations are enough for any decimal.
+	for _, digits := range []int{1, 10, 20, 40, 80, 130} {
+		n, ok := new(big.Int).SetString("7"+strings.Repeat("3", digits-1), 10)
+		require.True(t, ok)
+		x, iterations := sqrtNewton(n)
+		require.LessOrEqual(t, iterations, 4, "%d digits", digits)
+
+		// x is the square root rounded down.
+		require.True(t, new(big.Int).Mul(x, x).Cmp(n) <= 0)
+		x1 := new(big.Int).Add(x, big.NewInt(1))
+		require.True(t, new(big.Int).Mul(x1, x1).Cmp(n) > 0)
+	}
+}
+
 func TestMaxOrMinMyDecimal(t *testing.T) {
 	t.Parallel()
 	type tcase struct {
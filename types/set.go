@@ -41,6 +41,11 @@ func (e Set) ToNumber() float64 {
 	return float64(e.Value)
 }
 
+// ToBitmask returns the bitmask of the elements of the Set, the inverse of ParseSetValue.
+func (e Set) ToBitmask() uint64 {
+	return e.Value
+}
+
 // Copy deep copy a Set.
 func (e Set) Copy() Set {
 	return Set{
@@ -49,6 +54,12 @@ func (e Set) Copy() Set {
 	}
 }
 
+// Equal returns whether e and other hold the same elements. Only the bitmask is compared, as the
+// Name of a Set built by hand may list the elements in any order.
+func (e Set) Equal(other Set) bool {
+	return e.Value == other.Value
+}
+
 // ParseSet creates a Set with name or value.
 func ParseSet(elems []string, name string, collation string) (Set, error) {
 	if setName, err := ParseSetName(elems, name, collation); err == nil {
@@ -53,6 +53,16 @@ func TestSet(t *testing.T) {
 		}
 	})
 
+	t.Run("Equal", func(t *testing.T) {
+		s1, err := ParseSetValue(elems, 5)
+		require.NoError(t, err)
+		s2 := Set{Name: "c,a", Value: 5}
+		require.NotEqual(t, s1.String(), s2.String())
+		require.True(t, s1.Equal(s2))
+		require.True(t, s2.Equal(s1))
+		require.False(t, s1.Equal(Set{Name: "a,c", Value: 1}))
+	})
+
 	t.Run("ParseSet_ci", func(t *testing.T) {
 		tests := []struct {
 			Name          string
@@ -105,4 +115,33 @@ func TestSet(t *testing.T) {
 			require.Error(t, err)
 		}
 	})
+
+	t.Run("ToBitmask", func(t *testing.T) {
+		for number := uint64(0); number < 1<<len(elems); number++ {
+			e, err := ParseSetValue(elems, number)
+			require.NoError(t, err)
+			require.Equal(t, number, e.ToBitmask())
+
+			// The Name lists the elements in definition order, and parses back to the same Set.
+			e1, err := ParseSetName(elems, e.Name, mysql.DefaultCollationName)
+			require.NoError(t, err)
+			require.Equal(t, e, e1)
+		}
+
+		e, err := ParseSetName(elems, "d,b", mysql.DefaultCollationName)
+		require.NoError(t, err)
+		require.Equal(t, uint64(0b1010), e.ToBitmask())
+		e1, err := ParseSetValue(elems, e.ToBitmask())
+		require.NoError(t, err)
+		require.Equal(t, Set{Name: "b,d", Value: 0b1010}, e1)
+
+		all := make([]string, 64)
+		for i := range all {
+			all[i] = string(rune('a'+i%26)) + string(rune('a'+i/26))
+		}
+		e, err = ParseSetValue(all, 1<<63|1)
+		require.NoError(t, err)
+		require.Equal(t, "aa,lc", e.Name)
+		require.Equal(t, uint64(1<<63|1), e.ToBitmask())
+	})
 }
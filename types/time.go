@@ -277,6 +277,25 @@ func NewTime(coreTime CoreTime, tp uint8, fsp int8) Time {
 	return t
 }
 
+// NewTimeFromUnixMicros constructs a DATETIME from the microseconds since the Unix epoch, with the
+// wall clock in loc. The microseconds are rounded to fsp, and a time out of the years 1 to 9999
+// returns ErrDatetimeFunctionOverflow.
+func NewTimeFromUnixMicros(micros int64, fsp int8, loc *gotime.Location) (Time, error) {
+	fsp, err := CheckFsp(int(fsp))
+	if err != nil {
+		return ZeroDatetime, errors.Trace(err)
+	}
+	if loc == nil {
+		loc = gotime.UTC
+	}
+	t := gotime.Unix(micros/1e6, micros%1e6*1e3).Round(gotime.Duration(math.Pow10(9 - int(fsp)))).In(loc)
+	if t.Year() < 1 || t.Year() > 9999 {
+		return ZeroDatetime, ErrDatetimeFunctionOverflow.GenWithStackByArgs("datetime")
+	}
+	hour, minute, second := t.Clock()
+	return NewTime(FromDate(t.Year(), int(t.Month()), t.Day(), hour, minute, second, t.Nanosecond()/1000), mysql.TypeDatetime, fsp), nil
+}
+
 func (t Time) getFspTt() uint8 {
 	return uint8(uint64(t.coreTime) & fspTtBitFieldMask)
 }
@@ -336,6 +355,36 @@ func (t *Time) SetFsp(fsp int8) {
 	*(*uint64)(&t.coreTime) |= (uint64(fsp) << 1)
 }
 
+// WithFsp returns t with the fsp set to fsp, which is clamped to [0, MaxFsp]. Unlike RoundFrac,
+// the fractional seconds beyond fsp are truncated, and increasing the fsp doesn't change the value.
+// A DATE has no fsp and is returned as is.
+func (t Time) WithFsp(fsp int) Time {
+	if t.Type() == mysql.TypeDate {
+		return t
+	}
+	if fsp < 0 {
+		fsp = 0
+	} else if fsp > int(MaxFsp) {
+		fsp = int(MaxFsp)
+	}
+	microsecond := t.Microsecond()
+	microsecond -= microsecond % int(math.Pow10(int(MaxFsp)-fsp))
+	t.coreTime.setMicrosecond(uint32(microsecond))
+	t.SetFsp(int8(fsp))
+	return t
+}
+
+// Truncate returns t with the fractional seconds beyond fsp dropped without rounding, so that
+// truncating is monotonic. The fsp is checked by CheckFsp like TruncateFrac does. The type is
+// kept, and a DATE is returned as is.
+func (t Time) Truncate(fsp int) (Time, error) {
+	checkedFsp, err := CheckFsp(fsp)
+	if err != nil {
+		return t, errors.Trace(err)
+	}
+	return t.WithFsp(int(checkedFsp)), nil
+}
+
 // CoreTime returns core time.
 func (t Time) CoreTime() CoreTime {
 	return CoreTime(uint64(t.coreTime) & coreTimeBitFieldMask)
@@ -347,6 +396,18 @@ func (t *Time) SetCoreTime(ct CoreTime) {
 	*(*uint64)(&t.coreTime) |= (uint64(ct) & coreTimeBitFieldMask)
 }
 
+// SetCoreTimeChecked is like SetCoreTime, but checks that ct is valid for the type of t first,
+// and leaves t unchanged if it isn't.
+func (t *Time) SetCoreTimeChecked(sc *stmtctx.StatementContext, ct CoreTime) error {
+	tmp := *t
+	tmp.SetCoreTime(ct)
+	if err := tmp.check(sc); err != nil {
+		return errors.Trace(err)
+	}
+	*t = tmp
+	return nil
+}
+
 // CurrentTime returns current time with type tp.
 func CurrentTime(tp uint8) Time {
 	return NewTime(FromGoTime(gotime.Now()), tp, 0)
@@ -366,6 +427,104 @@ func (t *Time) ConvertTimeZone(from, to *gotime.Location) error {
 	return nil
 }
 
+// ConvertTimeZoneSkippingGap converts the time value from one timezone to another like
+// ConvertTimeZone, but a time skipped by a DST gap of from is moved forward to the end of the
+// gap like MySQL, e.g. 2021-03-14 02:30:00 in America/Los_Angeles is taken as 03:00:00.
+// A DATE has no time zone and is rejected.
+func (t *Time) ConvertTimeZoneSkippingGap(from, to *gotime.Location) error {
+	if t.Type() == mysql.TypeDate {
+		return errors.Errorf("cannot convert the time zone of a %s", TypeStr(t.Type()))
+	}
+	if t.IsZero() {
+		return nil
+	}
+	raw, err := t.GoTime(from)
+	if err != nil {
+		// UTC has no DST, so the time is in a gap of from if it's valid in UTC.
+		wall, err1 := t.GoTime(gotime.UTC)
+		if err1 != nil {
+			return errors.Trace(err)
+		}
+		raw = resolveWallClock(wall, from, DSTLater)
+	}
+	t.SetCoreTime(FromGoTime(raw.In(to)))
+	return nil
+}
+
+// DSTPolicy decides how a wall clock time is resolved when a DST transition of its time zone
+// makes it nonexistent or ambiguous.
+type DSTPolicy int
+
+const (
+	// DSTEarlier resolves an ambiguous time in a fall-back overlap to the earlier instant, and
+	// snaps a nonexistent time in a spring-forward gap backward to the last microsecond before it.
+	DSTEarlier DSTPolicy = iota
+	// DSTLater resolves an ambiguous time in a fall-back overlap to the later instant, and snaps
+	// a nonexistent time in a spring-forward gap forward to the first instant after it.
+	DSTLater
+)
+
+// ConvertTimeZoneWithDSTPolicy converts the time value from one timezone to another like
+// ConvertTimeZone, but a time in a DST gap or overlap of from is resolved by policy, rather than
+// failing or depending on which offset the time package picks.
+func (t *Time) ConvertTimeZoneWithDSTPolicy(from, to *gotime.Location, policy DSTPolicy) error {
+	if t.IsZero() {
+		return nil
+	}
+	// UTC has no DST, so only an invalid date fails.
+	wall, err := t.GoTime(gotime.UTC)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	t.SetCoreTime(FromGoTime(resolveWallClock(wall, from, policy).In(to)))
+	return nil
+}
+
+// resolveWallClock returns the instant whose wall clock in loc is the wall clock of wall in UTC.
+func resolveWallClock(wall gotime.Time, loc *gotime.Location, policy DSTPolicy) gotime.Time {
+	// A wall clock in a transition is ambiguous or nonexistent between the offsets before and
+	// after it, a day is long enough to get both of them.
+	_, offsetBefore := wall.Add(-24 * gotime.Hour).In(loc).Zone()
+	_, offsetAfter := wall.Add(24 * gotime.Hour).In(loc).Zone()
+	earlier := wall.Add(-gotime.Duration(offsetBefore) * gotime.Second)
+	later := wall.Add(-gotime.Duration(offsetAfter) * gotime.Second)
+	if earlier.After(later) {
+		earlier, later = later, earlier
+	}
+	isWallClock := func(instant gotime.Time) bool {
+		_, offset := instant.In(loc).Zone()
+		return instant.Add(gotime.Duration(offset) * gotime.Second).Equal(wall)
+	}
+	earlierValid, laterValid := isWallClock(earlier), isWallClock(later)
+	switch {
+	case earlierValid && laterValid:
+		if policy == DSTLater {
+			return later
+		}
+		return earlier
+	case earlierValid:
+		return earlier
+	case laterValid:
+		return later
+	}
+	// The wall clock is in a gap, find the transition between the two instants.
+	_, offsetLater := later.In(loc).Zone()
+	lo, hi := earlier.Unix(), later.Unix()
+	for lo+1 < hi {
+		mid := lo + (hi-lo)/2
+		if _, offset := gotime.Unix(mid, 0).In(loc).Zone(); offset == offsetLater {
+			hi = mid
+		} else {
+			lo = mid
+		}
+	}
+	transition := gotime.Unix(hi, 0)
+	if policy == DSTLater {
+		return transition
+	}
+	return transition.Add(-gotime.Microsecond)
+}
+
 func (t Time) String() string {
 	if t.Type() == mysql.TypeDate {
 		// We control the format, so no error would occur.
@@ -385,6 +544,35 @@ func (t Time) String() string {
 	return str
 }
 
+// AppendFormat appends the string form of t with the fractional seconds precision fsp to buf and
+// returns the extended buffer. It matches String when fsp is t.Fsp(), but doesn't allocate as
+// long as buf has enough capacity. Like String, a DATE never has the time part.
+func (t Time) AppendFormat(buf []byte, fsp int8) []byte {
+	buf = appendIntWidthN(buf, t.Year(), 4)
+	buf = append(buf, '-')
+	buf = appendIntWidthN(buf, t.Month(), 2)
+	buf = append(buf, '-')
+	buf = appendIntWidthN(buf, t.Day(), 2)
+	if t.Type() == mysql.TypeDate {
+		return buf
+	}
+	buf = append(buf, ' ')
+	buf = appendIntWidthN(buf, t.Hour(), 2)
+	buf = append(buf, ':')
+	buf = appendIntWidthN(buf, t.Minute(), 2)
+	buf = append(buf, ':')
+	buf = appendIntWidthN(buf, t.Second(), 2)
+	if fsp > 0 {
+		if fsp > MaxFsp {
+			fsp = MaxFsp
+		}
+		buf = append(buf, '.')
+		end := len(buf) + int(fsp)
+		buf = appendIntWidthN(buf, t.Microsecond(), 6)[:end]
+	}
+	return buf
+}
+
 // IsZero returns a boolean indicating whether the time is equal to ZeroCoreTime.
 func (t Time) IsZero() bool {
 	return compareTime(t.coreTime, ZeroCoreTime) == 0
@@ -395,6 +583,17 @@ func (t Time) InvalidZero() bool {
 	return t.Month() == 0 || t.Day() == 0
 }
 
+// IsValidForKey returns whether t can be encoded by ToPackedUint and decoded back without loss,
+// that is, every component fits in the range of a DATETIME. The zero time is valid as it is
+// encoded as 0, whether it is allowed at all depends on the SQL mode and is checked elsewhere.
+func (t Time) IsValidForKey() bool {
+	if t.IsZero() {
+		return true
+	}
+	return t.Year() <= 9999 && t.Month() <= 12 && t.Day() <= 31 && t.Hour() <= 23 &&
+		t.Minute() <= 59 && t.Second() <= 59 && t.Microsecond() <= 999999
+}
+
 const numberFormat = "%Y%m%d%H%i%s"
 const dateFormat = "%Y%m%d"
 
@@ -489,6 +688,55 @@ func (t Time) CompareString(sc *stmtctx.StatementContext, str string) (int, erro
 	return t.Compare(o), nil
 }
 
+// DaysBetween returns the number of days from o to t, the same as DATEDIFF(t, o).
+// The time-of-day part is ignored, and the result is negative if t is before o.
+func (t Time) DaysBetween(o Time) int64 {
+	return int64(DateDiff(t.coreTime, o.coreTime))
+}
+
+// epochDaynr is the day number of 1970-01-01, the same as TO_DAYS('1970-01-01').
+const epochDaynr = 719528
+
+// EpochDay returns the number of days from 1970-01-01 to t, which is negative if t is before 1970.
+// The time-of-day part is ignored.
+func (t Time) EpochDay() int64 {
+	return int64(calcDaynr(t.Year(), t.Month(), t.Day()) - epochDaynr)
+}
+
+// FromEpochDay returns the DATE which is n days from 1970-01-01, the inverse of Time.EpochDay.
+// It returns ZeroDate if the date is out of the range of 0001-01-01 to 9999-12-31.
+func FromEpochDay(n int64) Time {
+	daynr := n + epochDaynr
+	if daynr <= 0 || daynr > math.MaxInt32 {
+		return ZeroDate
+	}
+	year, month, day := getDateFromDaynr(uint(daynr))
+	if year == 0 || year > 9999 {
+		return ZeroDate
+	}
+	return NewTime(FromDate(int(year), int(month), int(day), 0, 0, 0, 0), mysql.TypeDate, DefaultFsp)
+}
+
+// FirstDayOfMonth returns the first day of the month of t as a DATE.
+// It returns an error if the month of t is zero.
+func (t Time) FirstDayOfMonth() (Time, error) {
+	year, month := t.Year(), t.Month()
+	if month < 1 || month > 12 {
+		return ZeroDate, ErrWrongValue.GenWithStackByArgs(DateTimeStr, t.String())
+	}
+	return NewTime(FromDate(year, month, 1, 0, 0, 0, 0), mysql.TypeDate, DefaultFsp), nil
+}
+
+// LastDayOfMonth returns the last day of the month of t as a DATE, the same as LAST_DAY(t).
+// It returns an error if the month of t is zero.
+func (t Time) LastDayOfMonth() (Time, error) {
+	year, month := t.Year(), t.Month()
+	if month < 1 || month > 12 {
+		return ZeroDate, ErrWrongValue.GenWithStackByArgs(DateTimeStr, t.String())
+	}
+	return NewTime(FromDate(year, month, GetLastDay(year, month), 0, 0, 0, 0), mysql.TypeDate, DefaultFsp), nil
+}
+
 // roundTime rounds the time value according to digits count specified by fsp.
 func roundTime(t gotime.Time, fsp int8) gotime.Time {
 	d := gotime.Duration(math.Pow10(9 - int(fsp)))
@@ -611,6 +859,9 @@ func (t Time) ToPackedUint() (uint64, error) {
 	if t.IsZero() {
 		return 0, nil
 	}
+	if !t.IsValidForKey() {
+		return 0, errors.Trace(ErrWrongValue.GenWithStackByArgs(DateTimeStr, t.String()))
+	}
 	year, month, day := tm.Year(), tm.Month(), tm.Day()
 	hour, minute, sec := tm.Hour(), tm.Minute(), tm.Second()
 	ymd := uint64(((year*13 + month) << 5) | day)
@@ -722,6 +973,123 @@ func (t *Time) Add(sc *stmtctx.StatementContext, d Duration) (Time, error) {
 	return ret, ret.Check(sc)
 }
 
+// CombineTimeAndGoDuration adds a Go duration to base. The duration is rounded to microseconds,
+// the result gets the max fsp if the duration has a fractional second, and a DATE becomes a
+// DATETIME if the duration is not a whole number of days.
+func CombineTimeAndGoDuration(sc *stmtctx.StatementContext, base Time, d gotime.Duration) (Time, error) {
+	dur := Duration{Duration: d.Round(gotime.Microsecond), Fsp: DefaultFsp}
+	if dur.Duration%gotime.Second != 0 {
+		dur.Fsp = MaxFsp
+	}
+	if base.Type() == mysql.TypeDate && dur.Duration%(24*gotime.Hour) != 0 {
+		base.SetType(mysql.TypeDatetime)
+	}
+	return base.Add(sc, dur)
+}
+
+// AddInterval adds an interval to t, the same as DATE_ADD(t, INTERVAL interval unit).
+// The unit is one of the units accepted by ParseDurationValue, e.g. "DAY", "WEEK" or "YEAR_MONTH".
+func (t Time) AddInterval(sc *stmtctx.StatementContext, unit, interval string) (Time, error) {
+	year, month, day, nano, err := ParseDurationValue(unit, interval)
+	if err != nil {
+		return ZeroTime, errors.Trace(err)
+	}
+	return t.addInterval(sc, year, month, day, nano)
+}
+
+// SubInterval subtracts an interval from t, the same as DATE_SUB(t, INTERVAL interval unit).
+// All the components of a composite unit like YEAR_MONTH are negated together.
+func (t Time) SubInterval(sc *stmtctx.StatementContext, unit, interval string) (Time, error) {
+	year, month, day, nano, err := ParseDurationValue(unit, interval)
+	if err != nil {
+		return ZeroTime, errors.Trace(err)
+	}
+	return t.addInterval(sc, -year, -month, -day, -nano)
+}
+
+// AddWeeks adds n weeks to t, the same as DATE_ADD(t, INTERVAL n WEEK).
+func (t Time) AddWeeks(sc *stmtctx.StatementContext, n int) (Time, error) {
+	return t.addInterval(sc, 0, 0, 7*int64(n), 0)
+}
+
+// addInterval is like addDate, but a DATE becomes a DATETIME if the interval has a time part.
+func (t Time) addInterval(sc *stmtctx.StatementContext, year, month, day, nano int64) (Time, error) {
+	if nano != 0 && t.Type() == mysql.TypeDate {
+		t.SetType(mysql.TypeDatetime)
+	}
+	return t.addDate(sc, year, month, day, nano)
+}
+
+// addDate adds years, months, days and nanoseconds to t like DATE_ADD does, an invalid date or
+// an overflow is returned as the error.
+func (t Time) addDate(sc *stmtctx.StatementContext, year, month, day, nano int64) (Time, error) {
+	res, _, err := t.AddDateWithErrHandler(sc, year, month, day, nano, func(err error) error { return err })
+	return res, err
+}
+
+// AddDateWithErrHandler adds years, months, days and nanoseconds to t like DATE_ADD does. If the
+// day overflows the target month, it is clamped to the last day of that month. The type of t is
+// kept, and the fsp of the result is 0 or 6 depending on whether it has a fractional part.
+// Every error is passed to handle, which returns nil to go on, e.g. after appending a warning.
+// An invalid date like 2021-02-30 is handled first, and the date normalized by Go is added to if
+// it's ignored. The result is NULL if an overflow is ignored.
+func (t Time) AddDateWithErrHandler(sc *stmtctx.StatementContext, year, month, day, nano int64, handle func(error) error) (Time, bool, error) {
+	goTime, err := t.GoTime(gotime.UTC)
+	if err := handle(err); err != nil {
+		return ZeroTime, true, errors.Trace(err)
+	}
+	goTime = AddDate(year, month, day, goTime.Add(gotime.Duration(nano)))
+
+	if goTime.Nanosecond() == 0 {
+		t.SetFsp(0)
+	} else {
+		t.SetFsp(MaxFsp)
+	}
+
+	// fix https://github.com/pingcap/tidb/issues/11329
+	if goTime.Year() == 0 {
+		hour, minute, second := goTime.Clock()
+		t.SetCoreTime(FromDate(0, 0, 0, hour, minute, second, goTime.Nanosecond()/1000))
+		return t, false, nil
+	}
+
+	if goTime.Year() < 0 || goTime.Year() > 9999 {
+		return ZeroTime, true, handle(ErrDatetimeFunctionOverflow.GenWithStackByArgs("datetime"))
+	}
+
+	t.SetCoreTime(FromGoTime(goTime))
+	overflow, err := DateTimeIsOverflow(sc, t)
+	if err := handle(err); err != nil {
+		return ZeroTime, true, errors.Trace(err)
+	}
+	if overflow {
+		return ZeroTime, true, handle(ErrDatetimeFunctionOverflow.GenWithStackByArgs("datetime"))
+	}
+	return t, false, nil
+}
+
+// NextValidDay returns the time of the next calendar day of t, the time of the day, the type and
+// the fsp are kept. The month and the year roll over, and invalid dates like 2021-02-29 are
+// skipped, e.g. the next day of both 2021-02-28 and 2021-02-30 is 2021-03-01.
+// The zero time or a date with a zero month or day doesn't have a next day.
+func (t Time) NextValidDay() (Time, error) {
+	if t.InvalidZero() {
+		return ZeroTime, errors.Trace(ErrWrongValue.GenWithStackByArgs(DateTimeStr, t.String()))
+	}
+	year, month, day := t.Year(), t.Month(), t.Day()+1
+	if day > GetLastDay(year, month) {
+		month, day = month+1, 1
+		if month > 12 {
+			year, month = year+1, 1
+		}
+	}
+	if year > 9999 {
+		return ZeroTime, ErrDatetimeFunctionOverflow.GenWithStackByArgs("datetime")
+	}
+	t.SetCoreTime(FromDate(year, month, day, t.Hour(), t.Minute(), t.Second(), t.Microsecond()))
+	return t, nil
+}
+
 // TimestampDiff returns t2 - t1 where t1 and t2 are date or datetime expressions.
 // The unit for the result (an integer) is given by the unit argument.
 // The legal values for unit are "YEAR" "QUARTER" "MONTH" "DAY" "HOUR" "SECOND" and so on.
@@ -729,6 +1097,31 @@ func TimestampDiff(unit string, t1 Time, t2 Time) int64 {
 	return timestampDiff(unit, t1.coreTime, t2.coreTime)
 }
 
+// FormatRelative formats the difference between t and now in a human-friendly way, e.g.
+// "2 days ago" or "in 3 hours". The largest unit of days, hours, minutes and seconds which fits in
+// the difference is used and the amount is truncated, a difference less than a second is "just now".
+func (t Time) FormatRelative(now Time) string {
+	seconds, _, neg := calcTimeTimeDiff(t.coreTime, now.coreTime, 1)
+	amount, unit := seconds, "second"
+	switch {
+	case seconds >= 24*3600:
+		amount, unit = seconds/(24*3600), "day"
+	case seconds >= 3600:
+		amount, unit = seconds/3600, "hour"
+	case seconds >= 60:
+		amount, unit = seconds/60, "minute"
+	case seconds == 0:
+		return "just now"
+	}
+	if amount > 1 {
+		unit += "s"
+	}
+	if neg {
+		return fmt.Sprintf("%d %s ago", amount, unit)
+	}
+	return fmt.Sprintf("in %d %s", amount, unit)
+}
+
 // ParseDateFormat parses a formatted date string and returns separated components.
 func ParseDateFormat(format string) []string {
 	format = strings.TrimSpace(format)
@@ -921,13 +1314,14 @@ func splitDateTime(format string) (seps []string, fracStr string, hasTZ bool, tz
 }
 
 // See https://dev.mysql.com/doc/refman/5.7/en/date-and-time-literals.html.
-func parseDatetime(sc *stmtctx.StatementContext, str string, fsp int8, isFloat bool) (Time, error) {
+func parseDatetime(sc *stmtctx.StatementContext, str string, fsp int8, isFloat, roundFrac bool, provided *uint8) (Time, error) {
 	var (
 		year, month, day, hour, minute, second, deltaHour, deltaMinute int
 		fracStr                                                        string
 		tzSign, tzHour, tzSep, tzMinute                                string
 		hasTZ, hhmmss                                                  bool
 		err                                                            error
+		mask                                                           uint8
 	)
 
 	seps, fracStr, hasTZ, tzSign, tzHour, tzSep, tzMinute, truncatedOrIncorrect := splitDateTime(str)
@@ -1030,6 +1424,10 @@ func parseDatetime(sc *stmtctx.StatementContext, str string, fsp int8, isFloat b
 			if seps[0] == "0" || (l >= 9 && l <= 14) {
 				hhmmss = true
 			}
+			mask = ProvidedYear | ProvidedMonth | ProvidedDay
+			if hhmmss {
+				mask |= ProvidedHour | ProvidedMinute | ProvidedSecond
+			}
 
 			break
 		}
@@ -1040,29 +1438,37 @@ func parseDatetime(sc *stmtctx.StatementContext, str string, fsp int8, isFloat b
 			// YYYYMMDDHHMMSS
 			_, err = fmt.Sscanf(seps[0], "%4d%2d%2d%2d%2d%2d", &year, &month, &day, &hour, &minute, &second)
 			hhmmss = true
+			mask = ProvidedYear | ProvidedMonth | ProvidedDay | ProvidedHour | ProvidedMinute | ProvidedSecond
 		case 12: // YYMMDDHHMMSS
 			_, err = fmt.Sscanf(seps[0], "%2d%2d%2d%2d%2d%2d", &year, &month, &day, &hour, &minute, &second)
 			year = adjustYear(year)
 			hhmmss = true
+			mask = ProvidedYear | ProvidedMonth | ProvidedDay | ProvidedHour | ProvidedMinute | ProvidedSecond
 		case 11: // YYMMDDHHMMS
 			_, err = fmt.Sscanf(seps[0], "%2d%2d%2d%2d%2d%1d", &year, &month, &day, &hour, &minute, &second)
 			year = adjustYear(year)
 			hhmmss = true
+			mask = ProvidedYear | ProvidedMonth | ProvidedDay | ProvidedHour | ProvidedMinute | ProvidedSecond
 		case 10: // YYMMDDHHMM
 			_, err = fmt.Sscanf(seps[0], "%2d%2d%2d%2d%2d", &year, &month, &day, &hour, &minute)
 			year = adjustYear(year)
+			mask = ProvidedYear | ProvidedMonth | ProvidedDay | ProvidedHour | ProvidedMinute
 		case 9: // YYMMDDHHM
 			_, err = fmt.Sscanf(seps[0], "%2d%2d%2d%2d%1d", &year, &month, &day, &hour, &minute)
 			year = adjustYear(year)
+			mask = ProvidedYear | ProvidedMonth | ProvidedDay | ProvidedHour | ProvidedMinute
 		case 8: // YYYYMMDD
 			_, err = fmt.Sscanf(seps[0], "%4d%2d%2d", &year, &month, &day)
+			mask = ProvidedYear | ProvidedMonth | ProvidedDay
 		case 7: // YYMMDDH
 			_, err = fmt.Sscanf(seps[0], "%2d%2d%2d%1d", &year, &month, &day, &hour)
 			year = adjustYear(year)
+			mask = ProvidedYear | ProvidedMonth | ProvidedDay | ProvidedHour
 		case 6, 5:
 			// YYMMDD && YYMMD
 			_, err = fmt.Sscanf(seps[0], "%2d%2d%2d", &year, &month, &day)
 			year = adjustYear(year)
+			mask = ProvidedYear | ProvidedMonth | ProvidedDay
 		default:
 			return ZeroDatetime, errors.Trace(ErrWrongValue.GenWithStackByArgs(TimeStr, str))
 		}
@@ -1079,10 +1485,13 @@ func parseDatetime(sc *stmtctx.StatementContext, str string, fsp int8, isFloat b
 				case 0:
 				case 1, 2:
 					_, err = fmt.Sscanf(fracStr, "%2d ", &hour)
+					mask |= ProvidedHour
 				case 3, 4:
 					_, err = fmt.Sscanf(fracStr, "%2d%2d ", &hour, &minute)
+					mask |= ProvidedHour | ProvidedMinute
 				default:
 					_, err = fmt.Sscanf(fracStr, "%2d%2d%2d ", &hour, &minute, &second)
+					mask |= ProvidedHour | ProvidedMinute | ProvidedSecond
 				}
 				truncatedOrIncorrect = err != nil
 			}
@@ -1092,6 +1501,7 @@ func parseDatetime(sc *stmtctx.StatementContext, str string, fsp int8, isFloat b
 				second = 0
 			} else {
 				_, err = fmt.Sscanf(fracStr, "%2d ", &second)
+				mask |= ProvidedSecond
 			}
 			truncatedOrIncorrect = err != nil
 		}
@@ -1100,21 +1510,32 @@ func parseDatetime(sc *stmtctx.StatementContext, str string, fsp int8, isFloat b
 			err = nil
 		}
 	case 2:
-		return ZeroDatetime, errors.Trace(ErrWrongValue.GenWithStackByArgs(DateTimeStr, str))
+		// YYYY-MM is only accepted when the caller asks which components are provided, the
+		// missing day is 1 so that the date is valid.
+		if provided == nil {
+			return ZeroDatetime, errors.Trace(ErrWrongValue.GenWithStackByArgs(DateTimeStr, str))
+		}
+		err = scanTimeArgs(seps, &year, &month)
+		day = 1
+		mask = ProvidedYear | ProvidedMonth
 	case 3:
 		// YYYY-MM-DD
 		err = scanTimeArgs(seps, &year, &month, &day)
+		mask = ProvidedYear | ProvidedMonth | ProvidedDay
 	case 4:
 		// YYYY-MM-DD HH
 		err = scanTimeArgs(seps, &year, &month, &day, &hour)
+		mask = ProvidedYear | ProvidedMonth | ProvidedDay | ProvidedHour
 	case 5:
 		// YYYY-MM-DD HH-MM
 		err = scanTimeArgs(seps, &year, &month, &day, &hour, &minute)
+		mask = ProvidedYear | ProvidedMonth | ProvidedDay | ProvidedHour | ProvidedMinute
 	case 6:
 		// We don't have fractional seconds part.
 		// YYYY-MM-DD HH-MM-SS
 		err = scanTimeArgs(seps, &year, &month, &day, &hour, &minute, &second)
 		hhmmss = true
+		mask = ProvidedYear | ProvidedMonth | ProvidedDay | ProvidedHour | ProvidedMinute | ProvidedSecond
 	default:
 		// For case like `2020-05-28 23:59:59 00:00:00`, the seps should be > 6, the reluctant parts should be truncated.
 		seps = seps[:6]
@@ -1124,10 +1545,14 @@ func parseDatetime(sc *stmtctx.StatementContext, str string, fsp int8, isFloat b
 		}
 		err = scanTimeArgs(seps, &year, &month, &day, &hour, &minute, &second)
 		hhmmss = true
+		mask = ProvidedYear | ProvidedMonth | ProvidedDay | ProvidedHour | ProvidedMinute | ProvidedSecond
 	}
 	if err != nil {
 		return ZeroDatetime, errors.Trace(err)
 	}
+	if provided != nil {
+		*provided = mask
+	}
 
 	// If str is sepereated by delimiters, the first one is year, and if the year is 1/2 digit,
 	// we should adjust it.
@@ -1145,7 +1570,11 @@ func parseDatetime(sc *stmtctx.StatementContext, str string, fsp int8, isFloat b
 	if hhmmss {
 		// If input string is "20170118.999", without hhmmss, fsp is meaningless.
 		// TODO: this case is not only meaningless, but erroneous, please confirm.
-		microsecond, overflow, err = ParseFrac(fracStr, fsp)
+		if roundFrac {
+			microsecond, overflow, err = ParseFrac(fracStr, fsp)
+		} else {
+			microsecond, err = parseFracTruncate(fracStr, fsp)
+		}
 		if err != nil {
 			return ZeroDatetime, errors.Trace(err)
 		}
@@ -1275,6 +1704,31 @@ func NewDuration(hour, minute, second, microsecond int, fsp int8) Duration {
 	}
 }
 
+// NewDurationStrict is like NewDuration, but rejects a component out of its range instead of
+// carrying it over, e.g, 00:60:00 is not 01:00:00. The hour is at most 838 like MaxTime, and the
+// error tells which component is wrong, e.g, "Incorrect minute value: '60'".
+func NewDurationStrict(hour, minute, second, microsecond int, fsp int8) (Duration, error) {
+	fsp, err := CheckFsp(int(fsp))
+	if err != nil {
+		return ZeroDuration, errors.Trace(err)
+	}
+	switch {
+	case hour < 0 || hour > TimeMaxHour:
+		return ZeroDuration, ErrWrongValue.GenWithStackByArgs("hour", strconv.Itoa(hour))
+	case minute < 0 || minute > TimeMaxMinute:
+		return ZeroDuration, ErrWrongValue.GenWithStackByArgs("minute", strconv.Itoa(minute))
+	case second < 0 || second > TimeMaxSecond:
+		return ZeroDuration, ErrWrongValue.GenWithStackByArgs("second", strconv.Itoa(second))
+	case microsecond < 0 || microsecond > 999999:
+		return ZeroDuration, ErrWrongValue.GenWithStackByArgs("microsecond", strconv.Itoa(microsecond))
+	}
+	d := NewDuration(hour, minute, second, microsecond, fsp)
+	if d.Duration > MaxTime {
+		return ZeroDuration, ErrDatetimeFunctionOverflow.GenWithStackByArgs("time")
+	}
+	return d, nil
+}
+
 // Duration is the type for MySQL TIME type.
 type Duration struct {
 	gotime.Duration
@@ -1311,6 +1765,21 @@ func (d Duration) Add(v Duration) (Duration, error) {
 	return Duration{Duration: gotime.Duration(dsum), Fsp: v.Fsp}, nil
 }
 
+// AddScaled is like Add, but rounds the result to the given fsp rather than taking the larger
+// fsp of d and v, e.g. 00:00:01.4 + 00:00:00.65 with fsp 0 is 00:00:02.
+func (d Duration) AddScaled(v Duration, fsp int) (Duration, error) {
+	resFsp, err := CheckFsp(fsp)
+	if err != nil {
+		return Duration{}, errors.Trace(err)
+	}
+	dsum, err := d.Add(v)
+	if err != nil {
+		return Duration{}, errors.Trace(err)
+	}
+	rounded := dsum.Duration.Round(gotime.Duration(math.Pow10(9-int(resFsp))) * gotime.Nanosecond)
+	return Duration{Duration: rounded, Fsp: resFsp}, nil
+}
+
 // Sub subtracts d to d, returns a duration value.
 func (d Duration) Sub(v Duration) (Duration, error) {
 	if v == (Duration{}) {
@@ -1494,6 +1963,39 @@ func (d Duration) RoundFrac(fsp int8, loc *gotime.Location) (Duration, error) {
 	return Duration{Duration: nd, Fsp: fsp}, nil
 }
 
+// TruncateToUnit truncates d to the given unit, which is one of SECOND, MINUTE and HOUR,
+// zeroing all finer components. e.g, truncate("01:23:45", "MINUTE") -> 01:23:00
+func (d Duration) TruncateToUnit(unit string) (Duration, error) {
+	var m gotime.Duration
+	switch strings.ToUpper(unit) {
+	case "SECOND":
+		m = gotime.Second
+	case "MINUTE":
+		m = gotime.Minute
+	case "HOUR":
+		m = gotime.Hour
+	default:
+		return d, errors.Errorf("invalid unit %s", unit)
+	}
+	return Duration{Duration: d.Duration.Truncate(m), Fsp: d.Fsp}, nil
+}
+
+// Round rounds d to the nearest multiple of unit, which is one of a second, a minute and an hour,
+// and the result has no fractional seconds. Halfway values round away from zero, so a negative
+// duration rounds like its absolute value. e.g, round("00:00:30", MINUTE) -> 00:01:00
+func (d Duration) Round(unit gotime.Duration) (Duration, error) {
+	switch unit {
+	case gotime.Second, gotime.Minute, gotime.Hour:
+	default:
+		return d, errors.Errorf("invalid unit %s", unit)
+	}
+	ret := d.Duration.Round(unit)
+	if ret > MaxTime || ret < MinTime {
+		return d, ErrDatetimeFunctionOverflow.GenWithStackByArgs("time")
+	}
+	return Duration{Duration: ret, Fsp: 0}, nil
+}
+
 // Compare returns an integer comparing the Duration instant t to o.
 // If d is after o, returns 1, equal o, returns 0, before o, returns -1.
 func (d Duration) Compare(o Duration) int {
@@ -1781,6 +2283,147 @@ func ParseDuration(sc *stmtctx.StatementContext, str string, fsp int8) (Duration
 	return d.RoundFrac(fsp, sc.TimeZone)
 }
 
+// ParseDurationFromNum parses a duration from a number in [-]HHMMSS format. The digits are
+// aligned to the right, so 1234 is 00:12:34 and 123456 is 12:34:56. Numbers out of the TIME
+// range are parsed as a full DATETIME like ParseDuration does.
+func ParseDurationFromNum(sc *stmtctx.StatementContext, num int64, fsp int8) (Duration, error) {
+	if num > MaxDuration || num < -MaxDuration {
+		return ParseDuration(sc, strconv.FormatInt(num, 10), fsp)
+	}
+	fsp, err := CheckFsp(int(fsp))
+	if err != nil {
+		return ZeroDuration, errors.Trace(err)
+	}
+	negative := num < 0
+	if negative {
+		num = -num
+	}
+	hhmmss := [3]int{int(num / 10000), int(num/100) % 100, int(num % 100)}
+	if !checkHHMMSS(hhmmss) {
+		if negative {
+			num = -num
+		}
+		return ZeroDuration, ErrTruncatedWrongVal.GenWithStackByArgs("time", strconv.FormatInt(num, 10))
+	}
+	d := gotime.Duration(hhmmss[0]*3600+hhmmss[1]*60+hhmmss[2]) * gotime.Second
+	if negative {
+		d = -d
+	}
+	return Duration{Duration: d, Fsp: fsp}, nil
+}
+
+// ParseDurationISO parses an ISO 8601 duration in the PnYnMnDTnHnMnS form, e.g. "PT1H30M" or
+// "-P1DT2.5S". Years and months are rejected as a Duration has no calendar to resolve them, and
+// only the seconds may have a fraction, which is rounded to fsp. A duration out of the TIME range
+// is truncated to it with a warning appended to sc.
+func ParseDurationISO(sc *stmtctx.StatementContext, str string, fsp int8) (Duration, error) {
+	fsp, err := CheckFsp(int(fsp))
+	if err != nil {
+		return ZeroDuration, errors.Trace(err)
+	}
+	d, ok := parseISODuration(strings.TrimSpace(str))
+	if !ok {
+		return ZeroDuration, ErrTruncatedWrongVal.GenWithStackByArgs("time", str)
+	}
+	d = d.Round(gotime.Duration(math.Pow10(9-int(fsp))) * gotime.Nanosecond)
+	if d > MaxTime || d < MinTime {
+		sc.AppendWarning(ErrTruncatedWrongVal.GenWithStackByArgs("time", str))
+		if d < 0 {
+			d = MinTime
+		} else {
+			d = MaxTime
+		}
+	}
+	return Duration{Duration: d, Fsp: fsp}, nil
+}
+
+// parseISODuration parses str in the PnDTnHnMnS form with an optional leading sign. When a
+// component is out of the TIME range, the result is just beyond the range with the same sign.
+func parseISODuration(str string) (gotime.Duration, bool) {
+	negative := false
+	if len(str) > 0 && (str[0] == '-' || str[0] == '+') {
+		negative = str[0] == '-'
+		str = str[1:]
+	}
+	if len(str) < 2 || (str[0] != 'P' && str[0] != 'p') {
+		return 0, false
+	}
+	str = str[1:]
+
+	var (
+		d          gotime.Duration
+		overflow   bool
+		inTime     bool
+		hasValue   bool
+		lastUnit   = -1
+		units      = [...]byte{'D', 'H', 'M', 'S'}
+		unitLength = [...]gotime.Duration{24 * gotime.Hour, gotime.Hour, gotime.Minute, gotime.Second}
+	)
+	for len(str) > 0 {
+		if str[0] == 'T' || str[0] == 't' {
+			if inTime {
+				return 0, false
+			}
+			inTime, hasValue, str = true, false, str[1:]
+			continue
+		}
+		i := 0
+		for i < len(str) && isDigit(str[i]) {
+			i++
+		}
+		intPart := str[:i]
+		fracPart := ""
+		if i < len(str) && (str[i] == '.' || str[i] == ',') {
+			j := i + 1
+			for j < len(str) && isDigit(str[j]) {
+				j++
+			}
+			fracPart, i = str[i+1:j], j
+		}
+		if (intPart == "" && fracPart == "") || i >= len(str) {
+			return 0, false
+		}
+		unit := -1
+		for k, u := range units {
+			if str[i] == u || str[i] == u+'a'-'A' {
+				unit = k
+			}
+		}
+		// Y, and M before the T, are calendar units, D is the only one allowed in the date part.
+		if unit < 0 || (unit == 0) == inTime || unit <= lastUnit || (fracPart != "" && unit != 3) {
+			return 0, false
+		}
+		lastUnit, hasValue, str = unit, true, str[i+1:]
+
+		if intPart != "" {
+			v, err := strconv.ParseInt(intPart, 10, 64)
+			if err != nil || v > int64(MaxTime/unitLength[unit]) {
+				overflow = true
+			} else {
+				d += gotime.Duration(v) * unitLength[unit]
+			}
+		}
+		if fracPart != "" {
+			if len(fracPart) > 9 {
+				fracPart = fracPart[:9]
+			}
+			nanos, _ := strconv.Atoi(fracPart)
+			d += gotime.Duration(nanos * int(math.Pow10(9-len(fracPart))))
+		}
+	}
+	// A trailing T, or a bare P, has no value.
+	if !hasValue {
+		return 0, false
+	}
+	if overflow {
+		d = MaxTime + gotime.Second
+	}
+	if negative {
+		d = -d
+	}
+	return d, true
+}
+
 // TruncateOverflowMySQLTime truncates d when it overflows, and returns ErrTruncatedWrongVal.
 func TruncateOverflowMySQLTime(d gotime.Duration) (gotime.Duration, error) {
 	if d > MaxTime {
@@ -1925,7 +2568,36 @@ func parseDateTimeFromNum(sc *stmtctx.StatementContext, num int64) (Time, error)
 // The valid timestamp range is from '1970-01-01 00:00:01.000000' to '2038-01-19 03:14:07.999999'.
 // The valid date range is from '1000-01-01' to '9999-12-31'
 func ParseTime(sc *stmtctx.StatementContext, str string, tp byte, fsp int8) (Time, error) {
-	return parseTime(sc, str, tp, fsp, false)
+	return parseTime(sc, str, tp, fsp, false, true, nil)
+}
+
+// ParseTimeWithRoundFrac is like ParseTime, but if roundFrac is false, the fractional seconds
+// exceeding fsp are truncated instead of rounded, e.g. "12:00:00.9999" is 12:00:00 rather than
+// 12:00:01 in DATETIME(0). ParseTime is the same as roundFrac being true.
+func ParseTimeWithRoundFrac(sc *stmtctx.StatementContext, str string, tp byte, fsp int8, roundFrac bool) (Time, error) {
+	return parseTime(sc, str, tp, fsp, false, roundFrac, nil)
+}
+
+// The bits of the mask returned by ParseTimeWithProvidedMask.
+const (
+	ProvidedYear uint8 = 1 << iota
+	ProvidedMonth
+	ProvidedDay
+	ProvidedHour
+	ProvidedMinute
+	ProvidedSecond
+)
+
+// ParseTimeWithProvidedMask is like ParseTime, but also returns a mask of the Provided* bits of
+// the components present in str, so that the caller can fill the missing ones with defaults.
+// It also accepts a partial date like "2023-05", whose day is set to 1.
+func ParseTimeWithProvidedMask(sc *stmtctx.StatementContext, str string, tp byte, fsp int8) (Time, uint8, error) {
+	var provided uint8
+	t, err := parseTime(sc, str, tp, fsp, false, true, &provided)
+	if err != nil {
+		return t, 0, err
+	}
+	return t, provided, nil
 }
 
 // ParseTimeFromFloatString is similar to ParseTime, except that it's used to parse a float converted string.
@@ -1934,16 +2606,30 @@ func ParseTimeFromFloatString(sc *stmtctx.StatementContext, str string, tp byte,
 	if len(str) >= 3 && str[:3] == "0.0" {
 		return NewTime(ZeroCoreTime, tp, DefaultFsp), nil
 	}
-	return parseTime(sc, str, tp, fsp, true)
+	return parseTime(sc, str, tp, fsp, true, true, nil)
+}
+
+// ParseTimeFromDecimal parses a decimal like 20230101120000.500, the integer part is parsed as the
+// datetime and the fraction as the fractional seconds. If fsp is UnspecifiedFsp, it is derived
+// from the scale of dec.
+func ParseTimeFromDecimal(sc *stmtctx.StatementContext, dec *MyDecimal, tp byte, fsp int8) (Time, error) {
+	if fsp == UnspecifiedFsp {
+		_, frac := dec.PrecisionAndFrac()
+		if frac > int(MaxFsp) {
+			frac = int(MaxFsp)
+		}
+		fsp = int8(frac)
+	}
+	return ParseTimeFromFloatString(sc, dec.String(), tp, fsp)
 }
 
-func parseTime(sc *stmtctx.StatementContext, str string, tp byte, fsp int8, isFloat bool) (Time, error) {
+func parseTime(sc *stmtctx.StatementContext, str string, tp byte, fsp int8, isFloat, roundFrac bool, provided *uint8) (Time, error) {
 	fsp, err := CheckFsp(int(fsp))
 	if err != nil {
 		return NewTime(ZeroCoreTime, tp, DefaultFsp), errors.Trace(err)
 	}
 
-	t, err := parseDatetime(sc, str, fsp, isFloat)
+	t, err := parseDatetime(sc, str, fsp, isFloat, roundFrac, provided)
 	if err != nil {
 		return NewTime(ZeroCoreTime, tp, DefaultFsp), errors.Trace(err)
 	}
@@ -2007,6 +2693,17 @@ func ParseTimeFromNum(sc *stmtctx.StatementContext, num int64, tp byte, fsp int8
 	return t, nil
 }
 
+// ParseTimeFromNumStrict parses a formatted int64 like ParseTimeFromNum, but only the full
+// YYYYMMDD and YYYYMMDDHHMMSS forms are accepted. A shorter number like 1234 is ambiguous, as it
+// may be a year or a time, so it returns ErrWrongValue rather than being guessed.
+func ParseTimeFromNumStrict(sc *stmtctx.StatementContext, num int64, tp byte, fsp int8) (Time, error) {
+	str := strconv.FormatInt(num, 10)
+	if len(str) != 8 && len(str) != 14 {
+		return NewTime(ZeroCoreTime, tp, DefaultFsp), errors.Trace(ErrWrongValue.GenWithStackByArgs(DateTimeStr, str))
+	}
+	return ParseTimeFromNum(sc, num, tp, fsp)
+}
+
 // ParseDatetimeFromNum is a helper function wrapping ParseTimeFromNum with datetime type and default fsp.
 func ParseDatetimeFromNum(sc *stmtctx.StatementContext, num int64) (Time, error) {
 	return ParseTimeFromNum(sc, num, mysql.TypeDatetime, DefaultFsp)
@@ -2720,6 +3417,16 @@ func FormatIntWidthN(num, n int) string {
 	return string(padBytes) + numString
 }
 
+// appendIntWidthN is like FormatIntWidthN, but appends the result to buf.
+func appendIntWidthN(buf []byte, num, n int) []byte {
+	for w, v := 1, num; w < n; w++ {
+		if v /= 10; v == 0 {
+			buf = append(buf, '0')
+		}
+	}
+	return strconv.AppendInt(buf, int64(num), 10)
+}
+
 func abbrDayOfMonth(day int) string {
 	var str string
 	switch day {
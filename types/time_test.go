@@ -17,6 +17,7 @@ package types_test
 import (
 	"fmt"
 	"math"
+	"strconv"
 	"testing"
 	"time"
 	"unsafe"
@@ -620,6 +621,66 @@ func TestCodec(t *testing.T) {
 	}
 }
 
+func TestParseTimeZeroInDate(t *testing.T) {
+	t.Parallel()
+	tbl := []struct {
+		input string
+		ret   string
+	}{
+		{"2023-00-05", "2023-00-05 00:00:00"},
+		{"2023-05-00", "2023-05-00 00:00:00"},
+		{"2023-00-00 12:00:00", "2023-00-00 12:00:00"},
+	}
+	for _, tt := range tbl {
+		// NO_ZERO_IN_DATE rejects a zero month or day.
+		sc := &stmtctx.StatementContext{TimeZone: time.UTC}
+		_, err := types.ParseTime(sc, tt.input, mysql.TypeDatetime, 0)
+		require.True(t, types.ErrWrongValue.Equal(err), tt.input)
+
+		sc.IgnoreZeroInDate = true
+		ret, err := types.ParseTime(sc, tt.input, mysql.TypeDatetime, 0)
+		require.NoError(t, err)
+		require.Equal(t, tt.ret, ret.String())
+	}
+
+	// The all-zero date is left to the NO_ZERO_DATE rule rather than NO_ZERO_IN_DATE.
+	sc := &stmtctx.StatementContext{TimeZone: time.UTC}
+	ret, err := types.ParseTime(sc, "0000-00-00", mysql.TypeDatetime, 0)
+	require.NoError(t, err)
+	require.True(t, ret.IsZero())
+}
+
+func TestTimeIsValidForKey(t *testing.T) {
+	t.Parallel()
+	tbl := []struct {
+		ct    types.CoreTime
+		valid bool
+	}{
+		{types.ZeroCoreTime, true},
+		{types.FromDate(2021, 2, 28, 23, 59, 59, 999999), true},
+		{types.FromDate(9999, 12, 31, 23, 59, 59, 999999), true},
+		{types.FromDate(2021, 0, 0, 0, 0, 0, 0), true},
+		{types.FromDate(10000, 1, 1, 0, 0, 0, 0), false},
+		{types.FromDate(16383, 1, 1, 0, 0, 0, 0), false},
+		{types.FromDate(2021, 1, 1, 0, 0, 0, 1000000), false},
+		{types.FromDate(2021, 13, 1, 0, 0, 0, 0), false},
+		{types.FromDate(2021, 1, 1, 24, 0, 0, 0), false},
+	}
+	for i, tt := range tbl {
+		v := types.NewTime(tt.ct, mysql.TypeDatetime, types.MaxFsp)
+		require.Equal(t, tt.valid, v.IsValidForKey(), "%d", i)
+		packed, err := v.ToPackedUint()
+		if !tt.valid {
+			require.True(t, types.ErrWrongValue.Equal(err), "%d", i)
+			continue
+		}
+		require.NoError(t, err)
+		dest := types.NewTime(types.ZeroCoreTime, mysql.TypeDatetime, types.MaxFsp)
+		require.NoError(t, dest.FromPackedUint(packed))
+		require.Equal(t, v.String(), dest.String())
+	}
+}
+
 func TestParseTimeFromNum(t *testing.T) {
 	t.Parallel()
 	table := []struct {
@@ -694,6 +755,38 @@ func TestParseTimeFromNum(t *testing.T) {
 	}
 }
 
+func TestParseTimeFromNumStrict(t *testing.T) {
+	t.Parallel()
+	sc := &stmtctx.StatementContext{TimeZone: time.UTC}
+	tbl := []struct {
+		Input  int64
+		Tp     byte
+		Expect string
+	}{
+		{20230101, mysql.TypeDate, "2023-01-01"},
+		{20230101, mysql.TypeDatetime, "2023-01-01 00:00:00"},
+		{20230101123456, mysql.TypeDatetime, "2023-01-01 12:34:56"},
+		{19991231235959, mysql.TypeTimestamp, "1999-12-31 23:59:59"},
+	}
+	for _, tt := range tbl {
+		v, err := types.ParseTimeFromNumStrict(sc, tt.Input, tt.Tp, types.DefaultFsp)
+		require.NoError(t, err, tt.Input)
+		require.Equal(t, tt.Expect, v.String())
+	}
+
+	// The short forms are accepted by ParseTimeFromNum, but they are ambiguous.
+	for _, num := range []int64{0, 1234, 230101, 2301011234, 230101123456, -20230101, 202301011} {
+		_, err := types.ParseTimeFromNumStrict(sc, num, mysql.TypeDatetime, types.DefaultFsp)
+		require.True(t, types.ErrWrongValue.Equal(err), num)
+	}
+	_, err := types.ParseTimeFromNum(sc, 230101, mysql.TypeDatetime, types.DefaultFsp)
+	require.NoError(t, err)
+
+	// A full form must still be a valid date.
+	_, err = types.ParseTimeFromNumStrict(sc, 20231301, mysql.TypeDatetime, types.DefaultFsp)
+	require.Error(t, err)
+}
+
 func TestToNumber(t *testing.T) {
 	t.Parallel()
 	sc := mock.NewContext().GetSessionVars().StmtCtx
@@ -1147,6 +1240,51 @@ func TestConvertTimeZone(t *testing.T) {
 	}
 }
 
+func TestConvertTimeZoneSkippingGap(t *testing.T) {
+	t.Parallel()
+	losAngelesTz, err := time.LoadLocation("America/Los_Angeles")
+	require.NoError(t, err)
+	tests := []struct {
+		input  types.CoreTime
+		from   *time.Location
+		to     *time.Location
+		expect types.CoreTime
+	}{
+		// A time in the spring-forward gap is moved to the end of it, which is 03:00:00 PDT.
+		{types.FromDate(2021, 3, 14, 2, 30, 0, 0), losAngelesTz, time.UTC, types.FromDate(2021, 3, 14, 10, 0, 0, 0)},
+		{types.FromDate(2021, 3, 14, 2, 0, 0, 0), losAngelesTz, time.UTC, types.FromDate(2021, 3, 14, 10, 0, 0, 0)},
+		{types.FromDate(2021, 3, 14, 2, 59, 59, 999999), losAngelesTz, time.UTC, types.FromDate(2021, 3, 14, 10, 0, 0, 0)},
+		// Around the gap.
+		{types.FromDate(2021, 3, 14, 1, 59, 59, 0), losAngelesTz, time.UTC, types.FromDate(2021, 3, 14, 9, 59, 59, 0)},
+		{types.FromDate(2021, 3, 14, 3, 0, 0, 0), losAngelesTz, time.UTC, types.FromDate(2021, 3, 14, 10, 0, 0, 0)},
+		// The fall-back overlap and back.
+		{types.FromDate(2021, 11, 7, 8, 30, 0, 0), time.UTC, losAngelesTz, types.FromDate(2021, 11, 7, 1, 30, 0, 0)},
+		{types.FromDate(2021, 11, 7, 9, 30, 0, 0), time.UTC, losAngelesTz, types.FromDate(2021, 11, 7, 1, 30, 0, 0)},
+		{types.FromDate(2021, 11, 7, 1, 30, 0, 0), losAngelesTz, time.UTC, types.FromDate(2021, 11, 7, 8, 30, 0, 0)},
+	}
+	for _, tp := range []byte{mysql.TypeTimestamp, mysql.TypeDatetime} {
+		for _, test := range tests {
+			v := types.NewTime(test.input, tp, 6)
+			err := v.ConvertTimeZoneSkippingGap(test.from, test.to)
+			require.NoError(t, err)
+			require.Equal(t, types.NewTime(test.expect, tp, 6), v, "%s", types.NewTime(test.input, tp, 6))
+		}
+	}
+
+	// ConvertTimeZone still fails for a time in a gap.
+	v := types.NewTime(types.FromDate(2021, 3, 14, 2, 30, 0, 0), mysql.TypeTimestamp, 0)
+	require.Error(t, v.ConvertTimeZone(losAngelesTz, time.UTC))
+
+	// An invalid date is still an error.
+	v = types.NewTime(types.FromDate(2021, 2, 30, 0, 0, 0, 0), mysql.TypeTimestamp, 0)
+	require.Error(t, v.ConvertTimeZoneSkippingGap(losAngelesTz, time.UTC))
+
+	// A DATE has no time zone.
+	v = types.NewTime(types.FromDate(2021, 3, 14, 0, 0, 0, 0), mysql.TypeDate, 0)
+	require.Error(t, v.ConvertTimeZoneSkippingGap(time.UTC, losAngelesTz))
+	require.Equal(t, types.NewTime(types.FromDate(2021, 3, 14, 0, 0, 0, 0), mysql.TypeDate, 0), v)
+}
+
 func TestTimeAdd(t *testing.T) {
 	t.Parallel()
 	tbl := []struct {
@@ -1178,6 +1316,752 @@ func TestTimeAdd(t *testing.T) {
 	}
 }
 
+func TestParseTimeWithProvidedMask(t *testing.T) {
+	t.Parallel()
+	ymd := types.ProvidedYear | types.ProvidedMonth | types.ProvidedDay
+	all := ymd | types.ProvidedHour | types.ProvidedMinute | types.ProvidedSecond
+	tbl := []struct {
+		Arg  string
+		Ret  string
+		Mask uint8
+	}{
+		{"2023-05", "2023-05-01 00:00:00", types.ProvidedYear | types.ProvidedMonth},
+		{"2023-05-06", "2023-05-06 00:00:00", ymd},
+		{"2023-05-06 10", "2023-05-06 10:00:00", ymd | types.ProvidedHour},
+		{"2023-05-06 10:11", "2023-05-06 10:11:00", ymd | types.ProvidedHour | types.ProvidedMinute},
+		{"2023-05-06 10:11:12", "2023-05-06 10:11:12", all},
+		{"20230506", "2023-05-06 00:00:00", ymd},
+		{"230506.1011", "2023-05-06 10:11:00", ymd | types.ProvidedHour | types.ProvidedMinute},
+		{"20230506101112", "2023-05-06 10:11:12", all},
+	}
+
+	sc := &stmtctx.StatementContext{
+		TimeZone: time.UTC,
+	}
+	for _, tt := range tbl {
+		ret, mask, err := types.ParseTimeWithProvidedMask(sc, tt.Arg, mysql.TypeDatetime, types.DefaultFsp)
+		require.NoError(t, err, tt.Arg)
+		require.Equal(t, tt.Ret, ret.String(), tt.Arg)
+		require.Equal(t, tt.Mask, mask, tt.Arg)
+	}
+
+	// A partial date is only accepted when the mask is asked for.
+	_, err := types.ParseTime(sc, "2023-05", mysql.TypeDatetime, types.DefaultFsp)
+	require.Error(t, err)
+	_, mask, err := types.ParseTimeWithProvidedMask(sc, "2023-13", mysql.TypeDatetime, types.DefaultFsp)
+	require.Error(t, err)
+	require.Zero(t, mask)
+}
+
+func TestTimeWithFsp(t *testing.T) {
+	t.Parallel()
+	sc := &stmtctx.StatementContext{TimeZone: time.UTC}
+	tbl := []struct {
+		Arg string
+		Fsp int
+		Ret string
+	}{
+		{"2021-01-01 12:00:00.999999", 2, "2021-01-01 12:00:00.99"},
+		{"2021-01-01 12:00:00.999999", 0, "2021-01-01 12:00:00"},
+		{"2021-01-01 12:00:00.123", 6, "2021-01-01 12:00:00.123000"},
+		{"2021-01-01 12:00:00", 6, "2021-01-01 12:00:00.000000"},
+		{"2021-01-01 12:00:00.5", 7, "2021-01-01 12:00:00.500000"},
+		{"2021-01-01 12:00:00.5", -1, "2021-01-01 12:00:00"},
+	}
+	for _, tt := range tbl {
+		v, err := types.ParseTime(sc, tt.Arg, mysql.TypeDatetime, types.MaxFsp)
+		require.NoError(t, err)
+		ret := v.WithFsp(tt.Fsp)
+		require.Equal(t, tt.Ret, ret.String())
+		require.Equal(t, mysql.TypeDatetime, ret.Type())
+	}
+
+	// Increasing the fsp doesn't add precision back.
+	v, err := types.ParseTime(sc, "2021-01-01 12:00:00.123456", mysql.TypeTimestamp, types.MaxFsp)
+	require.NoError(t, err)
+	ret := v.WithFsp(3).WithFsp(6)
+	require.Equal(t, "2021-01-01 12:00:00.123000", ret.String())
+	require.Equal(t, mysql.TypeTimestamp, ret.Type())
+
+	d, err := types.ParseTime(sc, "2021-01-01", mysql.TypeDate, types.DefaultFsp)
+	require.NoError(t, err)
+	require.Equal(t, d, d.WithFsp(6))
+}
+
+func TestTimeTruncate(t *testing.T) {
+	t.Parallel()
+	sc := &stmtctx.StatementContext{TimeZone: time.UTC}
+	tbl := []struct {
+		Arg string
+		Fsp int
+		Ret string
+	}{
+		{"2021-01-01 12:00:00.999999", 2, "2021-01-01 12:00:00.99"},
+		{"2021-01-01 12:00:00.999999", 0, "2021-01-01 12:00:00"},
+		{"2021-01-01 23:59:59.999999", 5, "2021-01-01 23:59:59.99999"},
+		{"2021-01-01 12:00:00.123456", 6, "2021-01-01 12:00:00.123456"},
+		{"2021-01-01 12:00:00.1", 3, "2021-01-01 12:00:00.100"},
+	}
+	for _, tp := range []byte{mysql.TypeDatetime, mysql.TypeTimestamp} {
+		for _, tt := range tbl {
+			v, err := types.ParseTime(sc, tt.Arg, tp, types.MaxFsp)
+			require.NoError(t, err)
+			ret, err := v.Truncate(tt.Fsp)
+			require.NoError(t, err)
+			require.Equal(t, tt.Ret, ret.String())
+			require.Equal(t, tp, ret.Type())
+			require.Equal(t, int8(tt.Fsp), ret.Fsp())
+			// Truncating never moves the value forward.
+			require.LessOrEqual(t, ret.Compare(v), 0)
+		}
+	}
+
+	v, err := types.ParseTime(sc, "2021-01-01 12:00:00.5", mysql.TypeDatetime, types.MaxFsp)
+	require.NoError(t, err)
+	_, err = v.Truncate(-2)
+	require.Error(t, err)
+	ret, err := v.Truncate(int(types.UnspecifiedFsp))
+	require.NoError(t, err)
+	require.Equal(t, "2021-01-01 12:00:00", ret.String())
+	ret, err = v.Truncate(7)
+	require.NoError(t, err)
+	require.Equal(t, "2021-01-01 12:00:00.500000", ret.String())
+
+	d, err := types.ParseTime(sc, "2021-01-01", mysql.TypeDate, types.DefaultFsp)
+	require.NoError(t, err)
+	ret, err = d.Truncate(3)
+	require.NoError(t, err)
+	require.Equal(t, d, ret)
+}
+
+func TestNextValidDay(t *testing.T) {
+	t.Parallel()
+	tbl := []struct {
+		Arg types.CoreTime
+		Tp  byte
+		Ret string
+	}{
+		{types.FromDate(2021, 1, 30, 0, 0, 0, 0), mysql.TypeDate, "2021-01-31"},
+		{types.FromDate(2021, 1, 31, 0, 0, 0, 0), mysql.TypeDate, "2021-02-01"},
+		{types.FromDate(2021, 2, 28, 0, 0, 0, 0), mysql.TypeDate, "2021-03-01"},
+		{types.FromDate(2020, 2, 28, 0, 0, 0, 0), mysql.TypeDate, "2020-02-29"},
+		{types.FromDate(2021, 2, 30, 0, 0, 0, 0), mysql.TypeDate, "2021-03-01"},
+		{types.FromDate(2021, 4, 30, 0, 0, 0, 0), mysql.TypeDate, "2021-05-01"},
+		{types.FromDate(2021, 12, 31, 0, 0, 0, 0), mysql.TypeDate, "2022-01-01"},
+		{types.FromDate(2021, 12, 31, 23, 59, 59, 999999), mysql.TypeDatetime, "2022-01-01 23:59:59.999999"},
+		{types.FromDate(2021, 6, 30, 12, 0, 0, 0), mysql.TypeTimestamp, "2021-07-01 12:00:00.000000"},
+	}
+	for _, tt := range tbl {
+		v := types.NewTime(tt.Arg, tt.Tp, types.MaxFsp)
+		ret, err := v.NextValidDay()
+		require.NoError(t, err)
+		require.Equal(t, tt.Ret, ret.String())
+		require.Equal(t, tt.Tp, ret.Type())
+	}
+
+	for _, ct := range []types.CoreTime{types.ZeroCoreTime, types.FromDate(2021, 0, 1, 0, 0, 0, 0), types.FromDate(2021, 1, 0, 0, 0, 0, 0)} {
+		_, err := types.NewTime(ct, mysql.TypeDate, 0).NextValidDay()
+		require.True(t, types.ErrWrongValue.Equal(err))
+	}
+	_, err := types.NewTime(types.FromDate(9999, 12, 31, 0, 0, 0, 0), mysql.TypeDate, 0).NextValidDay()
+	require.True(t, types.ErrDatetimeFunctionOverflow.Equal(err))
+}
+
+func TestConvertToTimeDSTAware(t *testing.T) {
+	t.Parallel()
+	newYork, err := time.LoadLocation("America/New_York")
+	require.NoError(t, err)
+	sc := &stmtctx.StatementContext{TimeZone: time.UTC}
+	tbl := []struct {
+		Arg     string
+		Earlier string
+		Later   string
+	}{
+		// 02:00 jumps to 03:00 in the spring.
+		{"2021-03-14 02:30:00", "2021-03-14 06:59:59.999999", "2021-03-14 07:00:00.000000"},
+		{"2021-03-14 02:00:00", "2021-03-14 06:59:59.999999", "2021-03-14 07:00:00.000000"},
+		{"2021-03-14 01:59:59", "2021-03-14 06:59:59.000000", "2021-03-14 06:59:59.000000"},
+		{"2021-03-14 03:00:00", "2021-03-14 07:00:00.000000", "2021-03-14 07:00:00.000000"},
+		// 02:00 goes back to 01:00 in the fall, so 01:30 happens twice.
+		{"2021-11-07 01:30:00", "2021-11-07 05:30:00.000000", "2021-11-07 06:30:00.000000"},
+		{"2021-07-01 12:00:00.5", "2021-07-01 16:00:00.500000", "2021-07-01 16:00:00.500000"},
+	}
+	for _, tt := range tbl {
+		d := types.NewStringDatum(tt.Arg)
+		ret, err := d.ConvertToTimeDSTAware(sc, newYork, types.DSTEarlier)
+		require.NoError(t, err)
+		require.Equal(t, tt.Earlier, ret.String(), tt.Arg)
+		require.Equal(t, mysql.TypeTimestamp, ret.Type())
+
+		ret, err = d.ConvertToTimeDSTAware(sc, newYork, types.DSTLater)
+		require.NoError(t, err)
+		require.Equal(t, tt.Later, ret.String(), tt.Arg)
+	}
+
+	d := types.NewStringDatum("2021-02-30 00:00:00")
+	_, err = d.ConvertToTimeDSTAware(sc, newYork, types.DSTEarlier)
+	require.Error(t, err)
+}
+
+func TestFormatRelative(t *testing.T) {
+	t.Parallel()
+	now := types.NewTime(types.FromDate(2021, 6, 15, 12, 0, 0, 0), mysql.TypeDatetime, 0)
+	tbl := []struct {
+		Arg types.CoreTime
+		Ret string
+	}{
+		{types.FromDate(2021, 6, 15, 12, 0, 0, 0), "just now"},
+		{types.FromDate(2021, 6, 15, 12, 0, 0, 999999), "just now"},
+		{types.FromDate(2021, 6, 15, 11, 59, 59, 0), "1 second ago"},
+		{types.FromDate(2021, 6, 15, 12, 0, 59, 0), "in 59 seconds"},
+		{types.FromDate(2021, 6, 15, 12, 1, 0, 0), "in 1 minute"},
+		{types.FromDate(2021, 6, 15, 11, 0, 1, 0), "59 minutes ago"},
+		{types.FromDate(2021, 6, 15, 11, 0, 0, 0), "1 hour ago"},
+		{types.FromDate(2021, 6, 15, 15, 30, 0, 0), "in 3 hours"},
+		{types.FromDate(2021, 6, 16, 11, 59, 59, 0), "in 23 hours"},
+		{types.FromDate(2021, 6, 16, 12, 0, 0, 0), "in 1 day"},
+		{types.FromDate(2021, 6, 13, 0, 0, 0, 0), "2 days ago"},
+		{types.FromDate(2020, 6, 15, 12, 0, 0, 0), "365 days ago"},
+	}
+	for _, tt := range tbl {
+		v := types.NewTime(tt.Arg, mysql.TypeDatetime, types.MaxFsp)
+		require.Equal(t, tt.Ret, v.FormatRelative(now), v.String())
+	}
+}
+
+func TestCombineTimeAndGoDuration(t *testing.T) {
+	t.Parallel()
+	tbl := []struct {
+		Arg string
+		Tp  byte
+		Dur time.Duration
+		Ret string
+	}{
+		{"2021-12-31 23:59:59", mysql.TypeDatetime, time.Second, "2022-01-01 00:00:00"},
+		{"2021-12-31 23:59:59", mysql.TypeDatetime, 1500 * time.Millisecond, "2022-01-01 00:00:00.500000"},
+		{"2021-12-31 23:59:59", mysql.TypeDatetime, 1234567 * time.Nanosecond, "2021-12-31 23:59:59.001235"},
+		{"2022-01-01 00:00:00", mysql.TypeDatetime, -time.Microsecond, "2021-12-31 23:59:59.999999"},
+		{"2022-01-01 00:00:00", mysql.TypeDatetime, -25 * time.Hour, "2021-12-30 23:00:00"},
+		{"2022-01-01", mysql.TypeDate, -48 * time.Hour, "2021-12-30"},
+		{"2022-01-01", mysql.TypeDate, -time.Hour, "2021-12-31 23:00:00"},
+		{"2022-01-01 00:00:00", mysql.TypeTimestamp, 90 * time.Minute, "2022-01-01 01:30:00"},
+	}
+
+	sc := &stmtctx.StatementContext{
+		TimeZone: time.UTC,
+	}
+	for _, tt := range tbl {
+		base, err := types.ParseTime(sc, tt.Arg, tt.Tp, types.DefaultFsp)
+		require.NoError(t, err)
+		ret, err := types.CombineTimeAndGoDuration(sc, base, tt.Dur)
+		require.NoError(t, err)
+		require.Equal(t, tt.Ret, ret.String())
+	}
+}
+
+func TestTimeAddWeeks(t *testing.T) {
+	t.Parallel()
+	tbl := []struct {
+		Arg   string
+		Weeks int
+		Ret   string
+	}{
+		{"2021-12-25", 1, "2022-01-01"},
+		{"2021-03-01", -1, "2021-02-22"},
+		{"2020-02-29", 52, "2021-02-27"},
+		{"2021-01-05 10:11:12", -2, "2020-12-22 10:11:12"},
+	}
+
+	sc := &stmtctx.StatementContext{
+		TimeZone: time.UTC,
+	}
+	for _, tt := range tbl {
+		v, err := types.ParseTime(sc, tt.Arg, mysql.TypeDatetime, types.DefaultFsp)
+		require.NoError(t, err)
+		ret, err := v.AddWeeks(sc, tt.Weeks)
+		require.NoError(t, err)
+		require.Equal(t, tt.Ret, ret.String()[:len(tt.Ret)])
+
+		ret2, err := v.AddInterval(sc, "WEEK", strconv.Itoa(tt.Weeks))
+		require.NoError(t, err)
+		require.Equal(t, 0, ret.Compare(ret2))
+	}
+
+	_, err := types.ZeroDatetime.AddWeeks(sc, 1)
+	require.Error(t, err)
+	_, err = types.NewTime(types.FromDate(9999, 12, 30, 0, 0, 0, 0), mysql.TypeDatetime, 0).AddWeeks(sc, 1)
+	require.True(t, types.ErrDatetimeFunctionOverflow.Equal(err))
+
+	ts, err := types.ParseTimestamp(sc, "2021-12-25 10:11:12")
+	require.NoError(t, err)
+	ret, err := ts.AddWeeks(sc, 1)
+	require.NoError(t, err)
+	require.Equal(t, mysql.TypeTimestamp, ret.Type())
+	require.Equal(t, "2022-01-01 10:11:12", ret.String())
+	_, err = types.NewTime(types.FromDate(2038, 1, 18, 0, 0, 0, 0), mysql.TypeTimestamp, 0).AddWeeks(sc, 1)
+	require.True(t, types.ErrDatetimeFunctionOverflow.Equal(err))
+}
+
+func TestTimeAddDateWithErrHandler(t *testing.T) {
+	t.Parallel()
+	sc := &stmtctx.StatementContext{
+		TimeZone: time.UTC,
+	}
+	var handled []error
+	ignore := func(err error) error {
+		if err != nil {
+			handled = append(handled, err)
+		}
+		return nil
+	}
+	fail := func(err error) error { return err }
+
+	// An invalid date is handled first, and the normalized date is added to if it's ignored.
+	invalid := types.NewTime(types.FromDate(2021, 2, 30, 0, 0, 0, 0), mysql.TypeDatetime, 0)
+	_, isNull, err := invalid.AddDateWithErrHandler(sc, 0, 0, 1, 0, fail)
+	require.True(t, isNull)
+	require.True(t, types.ErrWrongValue.Equal(err))
+	ret, isNull, err := invalid.AddDateWithErrHandler(sc, 0, 0, 1, 0, ignore)
+	require.NoError(t, err)
+	require.False(t, isNull)
+	require.Equal(t, "2021-03-03 00:00:00", ret.String())
+	require.Len(t, handled, 1)
+	require.True(t, types.ErrWrongValue.Equal(handled[0]))
+
+	// An ignored overflow makes the result NULL.
+	handled = nil
+	maxTime := types.NewTime(types.FromDate(9999, 12, 31, 0, 0, 0, 0), mysql.TypeDatetime, 0)
+	_, isNull, err = maxTime.AddDateWithErrHandler(sc, 0, 0, 1, 0, fail)
+	require.True(t, isNull)
+	require.True(t, types.ErrDatetimeFunctionOverflow.Equal(err))
+	_, isNull, err = maxTime.AddDateWithErrHandler(sc, 0, 0, 1, 0, ignore)
+	require.NoError(t, err)
+	require.True(t, isNull)
+	require.Len(t, handled, 1)
+	require.True(t, types.ErrDatetimeFunctionOverflow.Equal(handled[0]))
+
+	ret, isNull, err = maxTime.AddDateWithErrHandler(sc, 0, 0, -1, int64(time.Second)/2, fail)
+	require.NoError(t, err)
+	require.False(t, isNull)
+	require.Equal(t, int8(6), ret.Fsp())
+	require.Equal(t, "9999-12-30 00:00:00.500000", ret.String())
+}
+
+func TestTimeSubInterval(t *testing.T) {
+	t.Parallel()
+	tbl := []struct {
+		Arg      string
+		Unit     string
+		Interval string
+		Ret      string
+	}{
+		{"2021-03-31", "MONTH", "1", "2021-02-28"},
+		{"2020-03-31", "MONTH", "1", "2020-02-29"},
+		{"2020-02-29", "YEAR", "1", "2019-02-28"},
+		{"2021-03-31", "YEAR_MONTH", "1-1", "2020-02-29"},
+		{"2021-03-31", "YEAR_MONTH", "-1-1", "2022-04-30"},
+		{"2021-01-01 00:00:00", "DAY_HOUR", "1 2", "2020-12-30 22:00:00"},
+		{"2021-01-01 00:00:00", "MINUTE_SECOND", "1:30", "2020-12-31 23:58:30"},
+	}
+
+	sc := &stmtctx.StatementContext{
+		TimeZone: time.UTC,
+	}
+	for _, tt := range tbl {
+		v, err := types.ParseTime(sc, tt.Arg, mysql.TypeDatetime, types.DefaultFsp)
+		require.NoError(t, err)
+		ret, err := v.SubInterval(sc, tt.Unit, tt.Interval)
+		require.NoError(t, err)
+		require.Equal(t, tt.Ret, ret.String()[:len(tt.Ret)])
+	}
+}
+
+func TestDurationAddScaled(t *testing.T) {
+	t.Parallel()
+	sc := &stmtctx.StatementContext{TimeZone: time.UTC}
+	tbl := []struct {
+		lhs string
+		rhs string
+		fsp int
+		ret string
+	}{
+		{"00:00:01.4", "00:00:00.65", 0, "00:00:02"},
+		{"00:00:01.4", "00:00:00.05", 0, "00:00:01"},
+		{"00:59:59.123456", "00:00:00.876", 3, "01:00:00.000"},
+		{"23:59:59.994", "00:00:00.0011", 2, "24:00:00.00"},
+		{"-00:00:01.4", "-00:00:00.1", 0, "-00:00:02"},
+		{"00:00:01.25", "00:00:01.25", 6, "00:00:02.500000"},
+	}
+	for _, tt := range tbl {
+		lhs, err := types.ParseDuration(sc, tt.lhs, types.MaxFsp)
+		require.NoError(t, err)
+		rhs, err := types.ParseDuration(sc, tt.rhs, types.MaxFsp)
+		require.NoError(t, err)
+		ret, err := lhs.AddScaled(rhs, tt.fsp)
+		require.NoError(t, err)
+		require.Equal(t, tt.ret, ret.String())
+		require.Equal(t, int8(tt.fsp), ret.Fsp)
+	}
+
+	d, err := types.ParseDuration(sc, "00:00:01", 0)
+	require.NoError(t, err)
+	_, err = d.AddScaled(d, -2)
+	require.Error(t, err)
+}
+
+func TestDurationTruncateToUnit(t *testing.T) {
+	t.Parallel()
+	sc := &stmtctx.StatementContext{TimeZone: time.UTC}
+	tbl := []struct {
+		input string
+		unit  string
+		ret   string
+	}{
+		{"01:23:45.678", "SECOND", "01:23:45.000"},
+		{"01:23:45.678", "MINUTE", "01:23:00.000"},
+		{"01:23:45.678", "HOUR", "01:00:00.000"},
+		{"-01:23:45.678", "minute", "-01:23:00.000"},
+		{"838:59:59", "HOUR", "838:00:00"},
+	}
+	for _, tt := range tbl {
+		d, err := types.ParseDuration(sc, tt.input, types.GetFsp(tt.input))
+		require.NoError(t, err)
+		ret, err := d.TruncateToUnit(tt.unit)
+		require.NoError(t, err)
+		require.Equal(t, tt.ret, ret.String())
+	}
+
+	d, err := types.ParseDuration(sc, "01:23:45", 0)
+	require.NoError(t, err)
+	_, err = d.TruncateToUnit("DAY")
+	require.Error(t, err)
+}
+
+func TestNewDurationStrict(t *testing.T) {
+	t.Parallel()
+	tbl := []struct {
+		hour, minute, second, microsecond int
+		fsp                               int8
+		ret                               string
+	}{
+		{0, 0, 0, 0, 0, "00:00:00"},
+		{12, 34, 56, 0, 0, "12:34:56"},
+		{1, 2, 3, 456789, 6, "01:02:03.456789"},
+		{838, 59, 59, 0, 0, "838:59:59"},
+	}
+	for _, tt := range tbl {
+		d, err := types.NewDurationStrict(tt.hour, tt.minute, tt.second, tt.microsecond, tt.fsp)
+		require.NoError(t, err)
+		require.Equal(t, tt.ret, d.String())
+		require.Equal(t, types.NewDuration(tt.hour, tt.minute, tt.second, tt.microsecond, tt.fsp), d)
+	}
+
+	// The error tells which component is wrong.
+	errTbl := []struct {
+		hour, minute, second, microsecond int
+		msg                               string
+	}{
+		{0, 60, 0, 0, "Incorrect minute value: '60'"},
+		{0, 0, 60, 0, "Incorrect second value: '60'"},
+		{839, 0, 0, 0, "Incorrect hour value: '839'"},
+		{0, -1, 0, 0, "Incorrect minute value: '-1'"},
+		{0, 0, 0, 1000000, "Incorrect microsecond value: '1000000'"},
+	}
+	for _, tt := range errTbl {
+		_, err := types.NewDurationStrict(tt.hour, tt.minute, tt.second, tt.microsecond, types.MaxFsp)
+		require.True(t, types.ErrWrongValue.Equal(err))
+		require.Contains(t, err.Error(), tt.msg)
+	}
+	_, err := types.NewDurationStrict(838, 59, 59, 1, types.MaxFsp)
+	require.True(t, types.ErrDatetimeFunctionOverflow.Equal(err))
+	_, err = types.NewDurationStrict(0, 0, 0, 0, 7)
+	require.Error(t, err)
+
+	// NewDuration carries the components over.
+	require.Equal(t, "01:00:00", types.NewDuration(0, 60, 0, 0, 0).String())
+}
+
+func TestDurationRound(t *testing.T) {
+	t.Parallel()
+	sc := &stmtctx.StatementContext{TimeZone: time.UTC}
+	tbl := []struct {
+		input string
+		unit  time.Duration
+		ret   string
+	}{
+		{"00:00:29.9", time.Minute, "00:00:00"},
+		{"00:00:30", time.Minute, "00:01:00"},
+		{"00:00:29.9", time.Second, "00:00:30"},
+		{"00:00:29.4", time.Second, "00:00:29"},
+		{"00:00:29.5", time.Second, "00:00:30"},
+		{"01:29:59.999999", time.Hour, "01:00:00"},
+		{"01:30:00", time.Hour, "02:00:00"},
+		{"-00:00:29.9", time.Minute, "00:00:00"},
+		{"-00:00:30", time.Minute, "-00:01:00"},
+		{"-00:00:29.5", time.Second, "-00:00:30"},
+		{"-01:30:00", time.Hour, "-02:00:00"},
+		{"838:59:59", time.Second, "838:59:59"},
+		{"838:29:59", time.Hour, "838:00:00"},
+	}
+	for _, tt := range tbl {
+		d, err := types.ParseDuration(sc, tt.input, types.GetFsp(tt.input))
+		require.NoError(t, err)
+		ret, err := d.Round(tt.unit)
+		require.NoError(t, err, tt.input)
+		require.Equal(t, tt.ret, ret.String(), tt.input)
+		require.Equal(t, int8(0), ret.Fsp)
+	}
+
+	d, err := types.ParseDuration(sc, "838:59:59", 0)
+	require.NoError(t, err)
+	_, err = d.Round(time.Hour)
+	require.True(t, types.ErrDatetimeFunctionOverflow.Equal(err))
+	_, err = d.Neg().Round(time.Minute)
+	require.True(t, types.ErrDatetimeFunctionOverflow.Equal(err))
+	for _, unit := range []time.Duration{0, time.Millisecond, 2 * time.Second, 24 * time.Hour} {
+		_, err = d.Round(unit)
+		require.Error(t, err)
+	}
+}
+
+func TestTimeDayOfMonthBoundaries(t *testing.T) {
+	t.Parallel()
+	sc := &stmtctx.StatementContext{TimeZone: time.UTC}
+	tbl := []struct {
+		input string
+		first string
+		last  string
+	}{
+		{"2020-02-15 10:11:12", "2020-02-01", "2020-02-29"},
+		{"2021-02-15 10:11:12", "2021-02-01", "2021-02-28"},
+		{"2000-02-01", "2000-02-01", "2000-02-29"},
+		{"1900-02-28", "1900-02-01", "1900-02-28"},
+		{"2021-12-31 23:59:59", "2021-12-01", "2021-12-31"},
+		{"2021-04-30", "2021-04-01", "2021-04-30"},
+	}
+	for _, tt := range tbl {
+		v, err := types.ParseTime(sc, tt.input, mysql.TypeDatetime, 0)
+		require.NoError(t, err)
+		first, err := v.FirstDayOfMonth()
+		require.NoError(t, err)
+		require.Equal(t, mysql.TypeDate, first.Type())
+		require.Equal(t, tt.first, first.String())
+		last, err := v.LastDayOfMonth()
+		require.NoError(t, err)
+		require.Equal(t, mysql.TypeDate, last.Type())
+		require.Equal(t, tt.last, last.String())
+	}
+
+	_, err := types.ZeroDatetime.LastDayOfMonth()
+	require.Error(t, err)
+	_, err = types.ZeroDatetime.FirstDayOfMonth()
+	require.Error(t, err)
+}
+
+func TestParseTimeWithRoundFrac(t *testing.T) {
+	t.Parallel()
+	sc := &stmtctx.StatementContext{TimeZone: time.UTC}
+	tbl := []struct {
+		input    string
+		fsp      int8
+		round    string
+		truncate string
+	}{
+		{"2021-01-01 12:00:00.9999", 0, "2021-01-01 12:00:01", "2021-01-01 12:00:00"},
+		{"2021-12-31 23:59:59.9999", 0, "2022-01-01 00:00:00", "2021-12-31 23:59:59"},
+		{"2021-12-31 23:59:59.996", 2, "2022-01-01 00:00:00.00", "2021-12-31 23:59:59.99"},
+		{"2021-01-01 12:00:00.1234", 3, "2021-01-01 12:00:00.123", "2021-01-01 12:00:00.123"},
+		{"2021-01-01 12:00:00.5", 3, "2021-01-01 12:00:00.500", "2021-01-01 12:00:00.500"},
+		{"2021-01-01 12:00:00", 0, "2021-01-01 12:00:00", "2021-01-01 12:00:00"},
+	}
+	for _, tt := range tbl {
+		ret, err := types.ParseTimeWithRoundFrac(sc, tt.input, mysql.TypeDatetime, tt.fsp, true)
+		require.NoError(t, err)
+		require.Equal(t, tt.round, ret.String())
+		def, err := types.ParseTime(sc, tt.input, mysql.TypeDatetime, tt.fsp)
+		require.NoError(t, err)
+		require.Equal(t, tt.round, def.String())
+
+		ret, err = types.ParseTimeWithRoundFrac(sc, tt.input, mysql.TypeDatetime, tt.fsp, false)
+		require.NoError(t, err)
+		require.Equal(t, tt.truncate, ret.String())
+	}
+}
+
+func TestTimeDaysBetween(t *testing.T) {
+	t.Parallel()
+	tbl := []struct {
+		a    types.CoreTime
+		b    types.CoreTime
+		days int64
+	}{
+		{types.FromDate(2021, 3, 1, 0, 0, 0, 0), types.FromDate(2021, 2, 28, 0, 0, 0, 0), 1},
+		{types.FromDate(2020, 3, 1, 0, 0, 0, 0), types.FromDate(2020, 2, 28, 0, 0, 0, 0), 2},
+		{types.FromDate(2021, 1, 1, 0, 0, 1, 0), types.FromDate(2020, 12, 31, 23, 59, 59, 0), 1},
+		{types.FromDate(2021, 1, 1, 23, 59, 59, 0), types.FromDate(2021, 1, 1, 0, 0, 0, 0), 0},
+		{types.FromDate(2022, 1, 1, 0, 0, 0, 0), types.FromDate(2021, 1, 1, 12, 0, 0, 0), 365},
+	}
+	for _, tt := range tbl {
+		a := types.NewTime(tt.a, mysql.TypeDatetime, 0)
+		b := types.NewTime(tt.b, mysql.TypeDatetime, 0)
+		require.Equal(t, tt.days, a.DaysBetween(b))
+		require.Equal(t, -tt.days, b.DaysBetween(a))
+	}
+}
+
+func TestTimeEpochDay(t *testing.T) {
+	t.Parallel()
+	tbl := []struct {
+		date types.CoreTime
+		days int64
+	}{
+		{types.FromDate(1970, 1, 1, 0, 0, 0, 0), 0},
+		{types.FromDate(1970, 1, 2, 0, 0, 0, 0), 1},
+		{types.FromDate(1969, 12, 31, 0, 0, 0, 0), -1},
+		{types.FromDate(1900, 1, 1, 0, 0, 0, 0), -25567},
+		{types.FromDate(2000, 2, 29, 0, 0, 0, 0), 11016},
+		{types.FromDate(2023, 6, 15, 0, 0, 0, 0), 19523},
+		{types.FromDate(1, 1, 1, 0, 0, 0, 0), -719162},
+		{types.FromDate(9999, 12, 31, 0, 0, 0, 0), 2932896},
+	}
+	for _, tt := range tbl {
+		date := types.NewTime(tt.date, mysql.TypeDate, 0)
+		require.Equal(t, tt.days, date.EpochDay(), date.String())
+		require.Equal(t, date, types.FromEpochDay(tt.days), date.String())
+
+		// The time-of-day part is ignored.
+		dt := types.NewTime(types.FromDate(tt.date.Year(), tt.date.Month(), tt.date.Day(), 23, 59, 59, 999999), mysql.TypeDatetime, 6)
+		require.Equal(t, tt.days, dt.EpochDay(), dt.String())
+	}
+
+	// Round trip.
+	for n := int64(-800000); n <= 3000000; n += 997 {
+		date := types.FromEpochDay(n)
+		if date.IsZero() {
+			require.True(t, n < -719162 || n > 2932896, "%d", n)
+			continue
+		}
+		require.Equal(t, n, date.EpochDay(), "%d", n)
+	}
+	require.Equal(t, types.ZeroDate, types.FromEpochDay(-719163))
+	require.Equal(t, types.ZeroDate, types.FromEpochDay(2932897))
+	require.Equal(t, types.ZeroDate, types.FromEpochDay(math.MinInt64))
+	require.Equal(t, types.ZeroDate, types.FromEpochDay(math.MaxInt64))
+}
+
+func TestParseTimeFromDecimal(t *testing.T) {
+	t.Parallel()
+	sc := &stmtctx.StatementContext{TimeZone: time.UTC}
+	tbl := []struct {
+		input string
+		tp    byte
+		fsp   int8
+		ret   string
+	}{
+		{"20230101.0", mysql.TypeDate, types.UnspecifiedFsp, "2023-01-01"},
+		{"20230101", mysql.TypeDatetime, types.UnspecifiedFsp, "2023-01-01 00:00:00"},
+		{"20230101120000.500", mysql.TypeDatetime, types.UnspecifiedFsp, "2023-01-01 12:00:00.500"},
+		{"20230101120000.500", mysql.TypeDatetime, 1, "2023-01-01 12:00:00.5"},
+		{"20230101120000.1234567", mysql.TypeDatetime, types.UnspecifiedFsp, "2023-01-01 12:00:00.123457"},
+	}
+	for _, tt := range tbl {
+		ret, err := types.ParseTimeFromDecimal(sc, types.NewDecFromStringForTest(tt.input), tt.tp, tt.fsp)
+		require.NoError(t, err)
+		require.Equal(t, tt.ret, ret.String())
+	}
+
+	_, err := types.ParseTimeFromDecimal(sc, types.NewDecFromStringForTest("20231301.5"), mysql.TypeDatetime, types.UnspecifiedFsp)
+	require.Error(t, err)
+	_, err = types.ParseTimeFromDecimal(sc, types.NewDecFromStringForTest("20230101126000"), mysql.TypeDatetime, types.UnspecifiedFsp)
+	require.Error(t, err)
+}
+
+func TestParseDurationFromNum(t *testing.T) {
+	t.Parallel()
+	sc := &stmtctx.StatementContext{TimeZone: time.UTC}
+	tbl := []struct {
+		num int64
+		ret string
+	}{
+		{0, "00:00:00"},
+		{5, "00:00:05"},
+		{1234, "00:12:34"},
+		{-1234, "-00:12:34"},
+		{123456, "12:34:56"},
+		{8385959, "838:59:59"},
+		{-8385959, "-838:59:59"},
+	}
+	for _, tt := range tbl {
+		dur, err := types.ParseDurationFromNum(sc, tt.num, types.DefaultFsp)
+		require.NoError(t, err)
+		require.Equal(t, tt.ret, dur.String())
+
+		fromStr, err := types.ParseDuration(sc, strconv.FormatInt(tt.num, 10), types.DefaultFsp)
+		require.NoError(t, err)
+		require.Equal(t, fromStr, dur)
+	}
+
+	for _, num := range []int64{1261, 1299, 126000, -6100} {
+		_, err := types.ParseDurationFromNum(sc, num, types.DefaultFsp)
+		require.True(t, types.ErrTruncatedWrongVal.Equal(err), "%d", num)
+	}
+}
+
+func TestParseDurationISO(t *testing.T) {
+	t.Parallel()
+	sc := &stmtctx.StatementContext{TimeZone: time.UTC}
+	tbl := []struct {
+		input string
+		fsp   int8
+		ret   string
+	}{
+		{"PT1H30M", 0, "01:30:00"},
+		{"pt1h30m", 0, "01:30:00"},
+		{"PT45S", 0, "00:00:45"},
+		{"P1D", 0, "24:00:00"},
+		{"P1DT2H3M4S", 0, "26:03:04"},
+		{"-PT1H", 0, "-01:00:00"},
+		{"PT1.5S", 0, "00:00:02"},
+		{"PT1.5S", 1, "00:00:01.5"},
+		{"PT0.1234567S", 6, "00:00:00.123457"},
+		{"PT1,25S", 2, "00:00:01.25"},
+		{"-PT2.5S", 0, "-00:00:03"},
+		{" PT59M59.9S ", 0, "01:00:00"},
+	}
+	for _, tt := range tbl {
+		dur, err := types.ParseDurationISO(sc, tt.input, tt.fsp)
+		require.NoError(t, err, tt.input)
+		require.Equal(t, tt.ret, dur.String(), tt.input)
+		require.Equal(t, tt.fsp, dur.Fsp)
+	}
+	require.Zero(t, sc.WarningCount())
+
+	// Years and months have no fixed length, so they are rejected with the malformed inputs.
+	for _, str := range []string{"", "P", "PT", "P1DT", "P1Y", "P1M", "P1W", "PT1H1H", "PT1M1H", "PT1.5M", "PT1H2D", "1H", "PT1HX"} {
+		_, err := types.ParseDurationISO(sc, str, types.DefaultFsp)
+		require.True(t, types.ErrTruncatedWrongVal.Equal(err), str)
+	}
+
+	// Out of range durations are truncated with a warning.
+	for _, tt := range []struct {
+		input string
+		ret   string
+	}{
+		{"P35D", "838:59:59"},
+		{"-PT839H", "-838:59:59"},
+		{"PT3020399.9S", "838:59:59"},
+		{"P99999999999999999999D", "838:59:59"},
+	} {
+		sc := &stmtctx.StatementContext{TimeZone: time.UTC}
+		dur, err := types.ParseDurationISO(sc, tt.input, types.DefaultFsp)
+		require.NoError(t, err, tt.input)
+		require.Equal(t, tt.ret, dur.String(), tt.input)
+		warnings := sc.GetWarnings()
+		require.Len(t, warnings, 1)
+		require.True(t, types.ErrTruncatedWrongVal.Equal(warnings[0].Err))
+	}
+}
+
 func TestTruncateOverflowMySQLTime(t *testing.T) {
 	t.Parallel()
 	v := types.MaxTime + 1
@@ -1480,6 +2364,44 @@ func TestInvalidZero(t *testing.T) {
 	require.False(t, in.InvalidZero())
 }
 
+func TestSetCoreTime(t *testing.T) {
+	t.Parallel()
+	sc := &stmtctx.StatementContext{TimeZone: time.UTC}
+	for _, tp := range []byte{mysql.TypeDatetime, mysql.TypeTimestamp, mysql.TypeDate} {
+		// Reuse one Time, only the core time changes.
+		tm := types.NewTime(types.ZeroCoreTime, tp, 3)
+		for _, ct := range []types.CoreTime{
+			types.FromDate(2019, 4, 12, 12, 0, 0, 0),
+			types.FromDate(2020, 2, 29, 23, 59, 59, 999000),
+			types.FromDate(2038, 1, 19, 3, 14, 7, 0),
+		} {
+			tm.SetCoreTime(ct)
+			require.Equal(t, ct, tm.CoreTime())
+			require.Equal(t, tp, tm.Type())
+			require.Equal(t, int8(3), tm.Fsp())
+
+			require.NoError(t, tm.SetCoreTimeChecked(sc, ct))
+			require.Equal(t, ct, tm.CoreTime())
+			require.Equal(t, tp, tm.Type())
+			require.Equal(t, int8(3), tm.Fsp())
+		}
+
+		// An invalid core time is rejected by SetCoreTimeChecked and t is left as it was.
+		before := tm
+		require.Error(t, tm.SetCoreTimeChecked(sc, types.FromDate(2021, 2, 30, 0, 0, 0, 0)))
+		require.Equal(t, before, tm)
+		tm.SetCoreTime(types.FromDate(2021, 2, 30, 0, 0, 0, 0))
+		require.Equal(t, types.FromDate(2021, 2, 30, 0, 0, 0, 0), tm.CoreTime())
+	}
+
+	// A timestamp out of range is invalid although the datetime is fine.
+	tm := types.NewTime(types.ZeroCoreTime, mysql.TypeTimestamp, 0)
+	require.Error(t, tm.SetCoreTimeChecked(sc, types.FromDate(2039, 1, 1, 0, 0, 0, 0)))
+	require.Equal(t, types.ZeroCoreTime, tm.CoreTime())
+	tm.SetType(mysql.TypeDatetime)
+	require.NoError(t, tm.SetCoreTimeChecked(sc, types.FromDate(2039, 1, 1, 0, 0, 0, 0)))
+}
+
 func TestGetFsp(t *testing.T) {
 	t.Parallel()
 	res := types.GetFsp("2019:04:12 14:00:00.123456")
@@ -1863,6 +2785,51 @@ func TestTimeSub(t *testing.T) {
 	}
 }
 
+func TestTimeSubMixedTypes(t *testing.T) {
+	t.Parallel()
+	losAngelesTz, err := time.LoadLocation("America/Los_Angeles")
+	require.NoError(t, err)
+	sc := &stmtctx.StatementContext{
+		TimeZone: losAngelesTz,
+	}
+	tbl := []struct {
+		t1  types.Time
+		t2  types.Time
+		ret types.Duration
+	}{
+		// A DATE has no time part.
+		{
+			types.NewTime(types.FromDate(2021, 3, 15, 0, 0, 0, 0), mysql.TypeDate, 0),
+			types.NewTime(types.FromDate(2021, 3, 14, 12, 0, 0, 500000), mysql.TypeDatetime, 1),
+			types.Duration{Duration: 11*time.Hour + 59*time.Minute + 59*time.Second + 500*time.Millisecond, Fsp: 1},
+		},
+		// The result is negative if t1 is earlier.
+		{
+			types.NewTime(types.FromDate(2021, 1, 1, 0, 0, 0, 0), mysql.TypeDatetime, 0),
+			types.NewTime(types.FromDate(2021, 1, 2, 1, 0, 0, 123000), mysql.TypeDatetime, 3),
+			types.Duration{Duration: -(25*time.Hour + 123*time.Millisecond), Fsp: 3},
+		},
+		// Timestamps are in the session time zone, where 2021-03-14 02:00:00 is skipped by DST.
+		{
+			types.NewTime(types.FromDate(2021, 3, 14, 3, 30, 0, 0), mysql.TypeTimestamp, 0),
+			types.NewTime(types.FromDate(2021, 3, 14, 1, 30, 0, 0), mysql.TypeTimestamp, 0),
+			types.Duration{Duration: time.Hour, Fsp: 0},
+		},
+		// While datetimes are wall clock times.
+		{
+			types.NewTime(types.FromDate(2021, 3, 14, 3, 30, 0, 0), mysql.TypeDatetime, 0),
+			types.NewTime(types.FromDate(2021, 3, 14, 1, 30, 0, 0), mysql.TypeDatetime, 0),
+			types.Duration{Duration: 2 * time.Hour, Fsp: 0},
+		},
+	}
+	for _, tt := range tbl {
+		require.Equal(t, tt.ret, tt.t1.Sub(sc, &tt.t2), "%s - %s", tt.t1, tt.t2)
+		neg := tt.ret
+		neg.Duration = -neg.Duration
+		require.Equal(t, neg, tt.t2.Sub(sc, &tt.t1), "%s - %s", tt.t2, tt.t1)
+	}
+}
+
 func TestCheckMonthDay(t *testing.T) {
 	t.Parallel()
 	dates := []struct {
@@ -1953,6 +2920,94 @@ func TestFromGoTime(t *testing.T) {
 
 }
 
+func TestNewTimeFromUnixMicros(t *testing.T) {
+	t.Parallel()
+	shanghai, err := time.LoadLocation("Asia/Shanghai")
+	require.NoError(t, err)
+	tbl := []struct {
+		micros int64
+		fsp    int8
+		loc    *time.Location
+		expect string
+	}{
+		{0, 6, time.UTC, "1970-01-01 00:00:00.000000"},
+		{0, 0, shanghai, "1970-01-01 08:00:00"},
+		{0, 0, nil, "1970-01-01 00:00:00"},
+		{1672531200123456, 6, time.UTC, "2023-01-01 00:00:00.123456"},
+		{1672531200123456, 3, shanghai, "2023-01-01 08:00:00.123"},
+		// Rounded rather than truncated.
+		{1672531200123556, 3, time.UTC, "2023-01-01 00:00:00.124"},
+		{1672531200999999, 0, time.UTC, "2023-01-01 00:00:01"},
+		{1672531200999999, 5, time.UTC, "2023-01-01 00:00:01.00000"},
+		{-1, 6, time.UTC, "1969-12-31 23:59:59.999999"},
+		{-1, 0, time.UTC, "1970-01-01 00:00:00"},
+		{-1500000, 0, time.UTC, "1969-12-31 23:59:59"},
+		{-62135596800000000, 0, time.UTC, "0001-01-01 00:00:00"},
+		{253402300799999999, 6, time.UTC, "9999-12-31 23:59:59.999999"},
+	}
+	for _, tt := range tbl {
+		v, err := types.NewTimeFromUnixMicros(tt.micros, tt.fsp, tt.loc)
+		require.NoError(t, err, tt.micros)
+		require.Equal(t, mysql.TypeDatetime, v.Type())
+		require.Equal(t, tt.fsp, v.Fsp())
+		require.Equal(t, tt.expect, v.String(), tt.micros)
+	}
+
+	overflows := []struct {
+		micros int64
+		fsp    int8
+		loc    *time.Location
+	}{
+		{-62135596800000001, 6, time.UTC},
+		{253402300799999999, 5, time.UTC},
+		{253402300799999999, 6, shanghai},
+		{math.MaxInt64, 6, time.UTC},
+		{math.MinInt64, 6, time.UTC},
+	}
+	for _, tt := range overflows {
+		_, err := types.NewTimeFromUnixMicros(tt.micros, tt.fsp, tt.loc)
+		require.True(t, types.ErrDatetimeFunctionOverflow.Equal(err), tt.micros)
+	}
+
+	_, err = types.NewTimeFromUnixMicros(0, 7, time.UTC)
+	require.Error(t, err)
+}
+
+func TestTimeAppendFormat(t *testing.T) {
+	t.Parallel()
+	coreTimes := []types.CoreTime{
+		types.ZeroCoreTime,
+		types.FromDate(1, 1, 1, 0, 0, 0, 0),
+		types.FromDate(2023, 1, 2, 3, 4, 5, 6),
+		types.FromDate(1999, 12, 31, 23, 59, 59, 999999),
+		types.FromDate(2023, 11, 30, 12, 0, 0, 120000),
+		types.FromDate(999, 0, 0, 1, 2, 3, 123456),
+	}
+	for _, ct := range coreTimes {
+		for _, tp := range []byte{mysql.TypeDate, mysql.TypeDatetime, mysql.TypeTimestamp} {
+			for fsp := int8(0); fsp <= types.MaxFsp; fsp++ {
+				tm := types.NewTime(ct, tp, fsp)
+				require.Equal(t, tm.String(), string(tm.AppendFormat(nil, fsp)))
+
+				// The result is appended, and the fsp of the time itself is ignored.
+				other := types.NewTime(ct, tp, types.MaxFsp-fsp)
+				buf := other.AppendFormat([]byte("prefix "), fsp)
+				require.Equal(t, "prefix "+tm.String(), string(buf))
+			}
+		}
+	}
+
+	tm := types.NewTime(types.FromDate(2023, 1, 2, 3, 4, 5, 6), mysql.TypeDatetime, types.MaxFsp)
+	require.Equal(t, "2023-01-02 03:04:05.000006", string(tm.AppendFormat(nil, 10)))
+	require.Equal(t, "2023-01-02 03:04:05", string(tm.AppendFormat(nil, -1)))
+
+	buf := make([]byte, 0, 64)
+	allocs := testing.AllocsPerRun(10, func() {
+		buf = tm.AppendFormat(buf[:0], types.MaxFsp)
+	})
+	require.Zero(t, allocs)
+}
+
 func TestGetTimezone(t *testing.T) {
 	t.Parallel()
 	cases := []struct {
@@ -2078,6 +3133,23 @@ func BenchmarkFormat(b *testing.B) {
 	}
 }
 
+func BenchmarkTimeAppendFormat(b *testing.B) {
+	t1 := types.NewTime(types.FromGoTime(time.Now()), mysql.TypeDatetime, types.MaxFsp)
+	b.Run("String", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			_ = t1.String()
+		}
+	})
+	b.Run("AppendFormat", func(b *testing.B) {
+		b.ReportAllocs()
+		buf := make([]byte, 0, 32)
+		for i := 0; i < b.N; i++ {
+			buf = t1.AppendFormat(buf[:0], types.MaxFsp)
+		}
+	})
+}
+
 func BenchmarkTimeAdd(b *testing.B) {
 	sc := &stmtctx.StatementContext{
 		TimeZone: time.UTC,
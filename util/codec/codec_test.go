@@ -827,19 +827,24 @@ func TestJSON(t *testing.T) {
 		d.SetMysqlJSON(j)
 		originalDatums = append(originalDatums, d)
 	}
+	// An opaque JSON keeps its type tag through encoding.
+	originalDatums = append(originalDatums, types.NewJSONDatum(json.CreateBinary(json.Opaque{TypeCode: mysql.TypeBlob, Buf: []byte{0xca, 0xfe}})))
 
 	buf := make([]byte, 0, 4096)
 	buf, err := encode(nil, buf, originalDatums, false)
 	require.NoError(t, err)
 
-	decodedDatums, err := Decode(buf, 2)
+	decodedDatums, err := Decode(buf, len(originalDatums))
 	require.NoError(t, err)
+	require.Len(t, decodedDatums, len(originalDatums))
 
 	for i := range decodedDatums {
-		lhs := originalDatums[i].GetMysqlJSON().String()
-		rhs := decodedDatums[i].GetMysqlJSON().String()
-		require.Equal(t, lhs, rhs)
+		lhs := originalDatums[i].GetMysqlJSON()
+		rhs := decodedDatums[i].GetMysqlJSON()
+		require.Equal(t, lhs.TypeCode, rhs.TypeCode)
+		require.Equal(t, lhs.String(), rhs.String())
 	}
+	require.Equal(t, originalDatums[2].GetMysqlJSON().GetOpaque(), decodedDatums[2].GetMysqlJSON().GetOpaque())
 }
 
 func TestCut(t *testing.T) {
@@ -1323,3 +1328,74 @@ func TestHashChunkColumns(t *testing.T) {
 		require.Equal(t, rowHash[2].Sum64(), vecHash[2].Sum64())
 	}
 }
+
+func TestEncodeRow(t *testing.T) {
+	t.Parallel()
+	j, err := json.ParseBinaryFromString(`{"a": [1, "2"]}`)
+	require.NoError(t, err)
+	dec := types.NewDecFromStringForTest("-123.4500")
+	tm := types.NewTime(types.FromDate(2021, 2, 30, 12, 34, 56, 789000), mysql.TypeDatetime, 3)
+	datums := []types.Datum{
+		types.NewIntDatum(-1),
+		{},
+		types.NewUintDatum(math.MaxUint64),
+		types.NewFloat32Datum(1.5),
+		types.NewFloat64Datum(-2.25),
+		types.NewCollationStringDatum("abc", "utf8mb4_general_ci"),
+		{},
+		types.NewBytesDatum([]byte{0, 1, 2}),
+		types.NewDecimalDatum(dec),
+		types.NewDurationDatum(types.Duration{Duration: -time.Hour - time.Millisecond, Fsp: 3}),
+		types.NewTimeDatum(tm),
+		types.NewTimeDatum(types.NewTime(types.ZeroCoreTime, mysql.TypeDate, 0)),
+		types.NewMysqlEnumDatum(types.Enum{Name: "b", Value: 2}),
+		types.NewMysqlSetDatum(types.Set{Name: "a,c", Value: 5}, "utf8mb4_bin"),
+		types.NewMysqlBitDatum(types.NewBinaryLiteralFromUint(5, 2)),
+		types.NewBinaryLiteralDatum(types.BinaryLiteral{0x61}),
+		types.NewJSONDatum(j),
+		{},
+	}
+	colIDs := make([]int64, len(datums))
+	for i := range colIDs {
+		colIDs[i] = int64(i*1000 - 5)
+	}
+	b, err := EncodeRow(datums, colIDs, nil)
+	require.NoError(t, err)
+
+	decodedIDs, decoded, err := DecodeRow(b)
+	require.NoError(t, err)
+	require.Equal(t, colIDs, decodedIDs)
+	require.Len(t, decoded, len(datums))
+	sc := &stmtctx.StatementContext{TimeZone: time.Local}
+	for i := range datums {
+		require.Equal(t, datums[i].Kind(), decoded[i].Kind(), i)
+		require.Equal(t, datums[i].Collation(), decoded[i].Collation(), i)
+		cmp, err := datums[i].Compare(sc, &decoded[i], collate.GetBinaryCollator())
+		require.NoError(t, err)
+		require.Equal(t, 0, cmp, i)
+	}
+	require.Equal(t, tm, decoded[10].GetMysqlTime())
+	require.Equal(t, datums[9].GetMysqlDuration(), decoded[9].GetMysqlDuration())
+	require.Equal(t, datums[12].GetMysqlEnum(), decoded[12].GetMysqlEnum())
+
+	// A NULL column only takes a bit, so a row of NULLs only holds the column ids and the bitmap.
+	nulls := make([]types.Datum, 9)
+	b, err = EncodeRow(nulls, []int64{1, 2, 3, 4, 5, 6, 7, 8, 9}, nil)
+	require.NoError(t, err)
+	require.Len(t, b, 1+9+2)
+	_, decoded, err = DecodeRow(b)
+	require.NoError(t, err)
+	for _, d := range decoded {
+		require.True(t, d.IsNull())
+	}
+
+	// Appends to the buffer.
+	b, err = EncodeRow(datums[:1], colIDs[:1], []byte("x"))
+	require.NoError(t, err)
+	require.Equal(t, byte('x'), b[0])
+
+	_, err = EncodeRow(datums, colIDs[:1], nil)
+	require.Error(t, err)
+	_, _, err = DecodeRow(b[1 : len(b)-1])
+	require.Error(t, err)
+}
@@ -0,0 +1,235 @@
+// Copyright 2021 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package codec
+
+import (
+	"time"
+
+	"github.com/pingcap/errors"
+	"github.com/pingcap/tidb/types"
+	"github.com/pingcap/tidb/types/json"
+)
+
+// EncodeRow encodes a row of datums and their column ids into a self-describing row, e.g. for
+// spilling it to the disk. Unlike tablecodec.EncodeRow, the kind, the collation and the fsp of
+// every datum are kept, so DecodeRow restores the same datums without knowing the field types.
+// Row layout: count, colID1, ..., colIDn, null bitmap, value1, ..., valuem.
+// A NULL column only takes a bit in the null bitmap, a non-NULL value starts with its kind.
+func EncodeRow(datums []types.Datum, colIDs []int64, buf []byte) ([]byte, error) {
+	if len(datums) != len(colIDs) {
+		return buf, errors.Errorf("EncodeRow error: data and columnID count not match %d vs %d", len(datums), len(colIDs))
+	}
+	b := EncodeUvarint(buf, uint64(len(datums)))
+	for _, id := range colIDs {
+		b = EncodeVarint(b, id)
+	}
+	bitmapOffset := len(b)
+	b = append(b, make([]byte, (len(datums)+7)/8)...)
+	for i := range datums {
+		d := &datums[i]
+		if d.IsNull() {
+			b[bitmapOffset+i/8] |= 1 << uint(i%8)
+			continue
+		}
+		b = append(b, d.Kind())
+		switch d.Kind() {
+		case types.KindInt64:
+			b = EncodeVarint(b, d.GetInt64())
+		case types.KindUint64:
+			b = EncodeUvarint(b, d.GetUint64())
+		case types.KindFloat32, types.KindFloat64:
+			b = EncodeFloat(b, d.GetFloat64())
+		case types.KindString:
+			b = EncodeCompactBytes(b, d.GetBytes())
+			b = EncodeCompactBytes(b, []byte(d.Collation()))
+		case types.KindBytes:
+			b = EncodeCompactBytes(b, d.GetBytes())
+		case types.KindMysqlDecimal:
+			var err error
+			b, err = EncodeDecimal(b, d.GetMysqlDecimal(), d.Length(), d.Frac())
+			if err != nil {
+				return b, errors.Trace(err)
+			}
+		case types.KindMysqlDuration:
+			dur := d.GetMysqlDuration()
+			b = EncodeVarint(b, int64(dur.Duration))
+			b = append(b, byte(dur.Fsp))
+		case types.KindMysqlTime:
+			t := d.GetMysqlTime()
+			b = EncodeUint(b, uint64(t.CoreTime()))
+			b = append(b, t.Type(), byte(t.Fsp()))
+		case types.KindMysqlEnum:
+			e := d.GetMysqlEnum()
+			b = EncodeUvarint(b, e.Value)
+			b = EncodeCompactBytes(b, []byte(e.Name))
+			b = EncodeCompactBytes(b, []byte(d.Collation()))
+		case types.KindMysqlSet:
+			s := d.GetMysqlSet()
+			b = EncodeUvarint(b, s.Value)
+			b = EncodeCompactBytes(b, []byte(s.Name))
+			b = EncodeCompactBytes(b, []byte(d.Collation()))
+		case types.KindMysqlBit, types.KindBinaryLiteral:
+			b = EncodeCompactBytes(b, d.GetBinaryLiteral())
+		case types.KindMysqlJSON:
+			j := d.GetMysqlJSON()
+			b = append(b, j.TypeCode)
+			b = EncodeCompactBytes(b, j.Value)
+		default:
+			return b, errors.Errorf("unsupport encode type %d", d.Kind())
+		}
+	}
+	return b, nil
+}
+
+// DecodeRow decodes a row encoded by EncodeRow, it returns the column ids and the datums.
+func DecodeRow(b []byte) ([]int64, []types.Datum, error) {
+	b, cnt, err := DecodeUvarint(b)
+	if err != nil {
+		return nil, nil, errors.Trace(err)
+	}
+	// Every column takes at least one byte for its id.
+	if cnt > uint64(len(b)) {
+		return nil, nil, errors.Errorf("invalid encoded row: %d columns in %d bytes", cnt, len(b))
+	}
+	colIDs := make([]int64, cnt)
+	for i := range colIDs {
+		b, colIDs[i], err = DecodeVarint(b)
+		if err != nil {
+			return nil, nil, errors.Trace(err)
+		}
+	}
+	bitmapLen := (len(colIDs) + 7) / 8
+	if len(b) < bitmapLen {
+		return nil, nil, errors.New("insufficient bytes to decode value")
+	}
+	nullBitmap := b[:bitmapLen]
+	b = b[bitmapLen:]
+	datums := make([]types.Datum, cnt)
+	for i := range datums {
+		if nullBitmap[i/8]&(1<<uint(i%8)) != 0 {
+			continue
+		}
+		b, err = decodeRowValue(b, &datums[i])
+		if err != nil {
+			return nil, nil, errors.Trace(err)
+		}
+	}
+	if len(b) > 0 {
+		return nil, nil, errors.Errorf("invalid encoded row: %d bytes remain", len(b))
+	}
+	return colIDs, datums, nil
+}
+
+func decodeRowValue(b []byte, d *types.Datum) ([]byte, error) {
+	if len(b) < 1 {
+		return b, errors.New("insufficient bytes to decode value")
+	}
+	kind := b[0]
+	b = b[1:]
+	var err error
+	switch kind {
+	case types.KindInt64:
+		var v int64
+		b, v, err = DecodeVarint(b)
+		d.SetInt64(v)
+	case types.KindUint64:
+		var v uint64
+		b, v, err = DecodeUvarint(b)
+		d.SetUint64(v)
+	case types.KindFloat32:
+		var v float64
+		b, v, err = DecodeFloat(b)
+		d.SetFloat32(float32(v))
+	case types.KindFloat64:
+		var v float64
+		b, v, err = DecodeFloat(b)
+		d.SetFloat64(v)
+	case types.KindString:
+		var v, collation []byte
+		if b, v, err = DecodeCompactBytes(b); err == nil {
+			b, collation, err = DecodeCompactBytes(b)
+		}
+		d.SetString(string(v), string(collation))
+	case types.KindBytes:
+		var v []byte
+		b, v, err = DecodeCompactBytes(b)
+		d.SetBytes(v)
+	case types.KindMysqlDecimal:
+		var (
+			dec             *types.MyDecimal
+			precision, frac int
+		)
+		b, dec, precision, frac, err = DecodeDecimal(b)
+		if err == nil {
+			d.SetMysqlDecimal(dec)
+			d.SetLength(precision)
+			d.SetFrac(frac)
+		}
+	case types.KindMysqlDuration:
+		var v int64
+		b, v, err = DecodeVarint(b)
+		if err == nil {
+			if len(b) < 1 {
+				return b, errors.New("insufficient bytes to decode value")
+			}
+			d.SetMysqlDuration(types.Duration{Duration: time.Duration(v), Fsp: int8(b[0])})
+			b = b[1:]
+		}
+	case types.KindMysqlTime:
+		var v uint64
+		b, v, err = DecodeUint(b)
+		if err == nil {
+			if len(b) < 2 {
+				return b, errors.New("insufficient bytes to decode value")
+			}
+			d.SetMysqlTime(types.NewTime(types.CoreTime(v), b[0], int8(b[1])))
+			b = b[2:]
+		}
+	case types.KindMysqlEnum, types.KindMysqlSet:
+		var (
+			v               uint64
+			name, collation []byte
+		)
+		if b, v, err = DecodeUvarint(b); err == nil {
+			if b, name, err = DecodeCompactBytes(b); err == nil {
+				b, collation, err = DecodeCompactBytes(b)
+			}
+		}
+		if kind == types.KindMysqlEnum {
+			d.SetMysqlEnum(types.Enum{Name: string(name), Value: v}, string(collation))
+		} else {
+			d.SetMysqlSet(types.Set{Name: string(name), Value: v}, string(collation))
+		}
+	case types.KindMysqlBit:
+		var v []byte
+		b, v, err = DecodeCompactBytes(b)
+		d.SetMysqlBit(v)
+	case types.KindBinaryLiteral:
+		var v []byte
+		b, v, err = DecodeCompactBytes(b)
+		d.SetBinaryLiteral(v)
+	case types.KindMysqlJSON:
+		if len(b) < 1 {
+			return b, errors.New("insufficient bytes to decode value")
+		}
+		typeCode := b[0]
+		var v []byte
+		b, v, err = DecodeCompactBytes(b[1:])
+		d.SetMysqlJSON(json.BinaryJSON{TypeCode: typeCode, Value: v})
+	default:
+		return b, errors.Errorf("invalid encoded row kind %v", kind)
+	}
+	return b, errors.Trace(err)
+}
@@ -323,6 +323,15 @@ func IsBinCollation(collate string) bool {
 		collate == charset.CollationUTF8 || collate == charset.CollationUTF8MB4
 }
 
+// IsBinCollator returns if the collator compares strings by their bytes, with or without padding.
+func IsBinCollator(c Collator) bool {
+	switch c.(type) {
+	case *binCollator, *binPaddingCollator:
+		return true
+	}
+	return false
+}
+
 func init() {
 	newCollatorMap = make(map[string]Collator)
 	newCollatorIDMap = make(map[int]Collator)